@@ -0,0 +1,116 @@
+package lzjson
+
+// Checker gets values of required type from a Node and records
+// every error, so that many values can be checked at once:
+//
+//	c := lzjson.NewChecker(json)
+//	name := c.String("name")
+//	age := c.Int("age")
+//	if err := c.Err(); err != nil {
+//	  // all the errors of name and age
+//	}
+type Checker struct {
+	node Node
+	errs Errors
+}
+
+// NewChecker returns a Checker of the node
+func NewChecker(n Node) *Checker {
+	return &Checker{node: n}
+}
+
+// maxInt is the maximum value of int
+const maxInt = int(^uint(0) >> 1)
+
+// get selects the value of the type. The error
+// is recorded if the value is undefined or mismatch
+func (c *Checker) get(sel, typ string) (Node, bool) {
+	n, err := Select(c.node, sel)
+	if err != nil {
+		c.errs = append(c.errs, Error{Path: "json" + nodePath(c.node), Err: err})
+		return NewNode(), false
+	}
+	b, err := definedValue(n)
+	if err == nil {
+		switch have := typeName(b); {
+		case typ == "" || typ == have:
+		case typ == "integer" && have == "number":
+			if f := n.Number(); !isInteger(b) || f > float64(maxInt) || f < float64(-maxInt-1) {
+				err = mismatchError{"integer", string(b)}
+			}
+		default:
+			err = mismatchError{typ, have}
+		}
+		if err != nil {
+			err = Error{Path: "json" + nodePath(n), Err: err}
+		}
+	}
+	if err != nil {
+		lerr, ok := err.(Error)
+		if !ok {
+			lerr = Error{Path: "json" + nodePath(n), Err: err}
+		}
+		c.errs = append(c.errs, lerr)
+		return n, false
+	}
+	return n, true
+}
+
+// Node returns the value at the selector, of any type
+func (c *Checker) Node(sel string) Node {
+	n, _ := c.get(sel, "")
+	return n
+}
+
+// String returns the string at the selector
+func (c *Checker) String(sel string) string {
+	if n, ok := c.get(sel, "string"); ok {
+		return n.String()
+	}
+	return ""
+}
+
+// Number returns the number at the selector
+func (c *Checker) Number(sel string) float64 {
+	if n, ok := c.get(sel, "number"); ok {
+		return n.Number()
+	}
+	return 0
+}
+
+// Int returns the integer at the selector
+func (c *Checker) Int(sel string) int {
+	if n, ok := c.get(sel, "integer"); ok {
+		return n.Int()
+	}
+	return 0
+}
+
+// Bool returns the bool at the selector
+func (c *Checker) Bool(sel string) bool {
+	if n, ok := c.get(sel, "bool"); ok {
+		return n.Bool()
+	}
+	return false
+}
+
+// Object returns the object at the selector
+func (c *Checker) Object(sel string) Node {
+	n, _ := c.get(sel, "object")
+	return n
+}
+
+// Array returns the array at the selector
+func (c *Checker) Array(sel string) Node {
+	n, _ := c.get(sel, "array")
+	return n
+}
+
+// Err returns all the recorded errors as Errors,
+// or nil if there is none
+func (c *Checker) Err() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return append(Errors(nil), c.errs...)
+}
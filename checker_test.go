@@ -0,0 +1,97 @@
+package lzjson_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+func TestChecker(t *testing.T) {
+	c := lzjson.NewChecker(lzjson.ParseString(`{
+		"name": "John",
+		"age": 42,
+		"score": 4.5,
+		"admin": true,
+		"tags": ["a", "b"],
+		"address": {"city": "Hong Kong"}
+	}`))
+
+	if want, have := "John", c.String("name"); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := 42, c.Int("age"); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := 4.5, c.Number("score"); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := true, c.Bool("admin"); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := "b", c.String("tags[1]"); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := 2, c.Array("tags").Len(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := "Hong Kong", c.Object("address").Get("city").String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := lzjson.TypeString, c.Node("address.city").Type(); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+	if err := c.Err(); err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+}
+
+func TestChecker_error(t *testing.T) {
+	c := lzjson.NewChecker(lzjson.ParseString(`{
+		"name": null,
+		"age": 4.5,
+		"count": 1e100,
+		"tags": "a",
+		"address": {"city": "Hong Kong"}
+	}`))
+
+	if want, have := "", c.String("name"); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := 0, c.Int("age"); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	c.Int("count")
+	c.Array("tags")
+	c.String("address.zip")
+	c.Bool("address.city.valid")
+	c.Number("email")
+	c.Node("tags[")
+
+	err := c.Err()
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+	want := `json.name: expected string, got null
+json.age: expected integer, got 4.5
+json.count: expected integer, got 1e100
+json.tags: expected array, got string
+json.address.zip: undefined
+json.address.city.valid: type mismatch (json.address.city is not an object)
+json.email: undefined
+json: invalid selector "tags[": unclosed bracket`
+	if have := err.Error(); want != have {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, have)
+	}
+
+	var lerr lzjson.Error
+	if !errors.As(err, &lerr) {
+		t.Fatalf("expected errors.As to find lzjson.Error")
+	}
+	if want, have := "json.name", lerr.Path; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := 8, len(err.(interface{ Unwrap() []error }).Unwrap()); want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+}
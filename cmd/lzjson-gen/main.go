@@ -0,0 +1,232 @@
+// Command lzjson-gen generates Go struct definitions from
+// sample JSON documents.
+//
+// Usage:
+//
+//	lzjson-gen [-name Root] [-package main] [file ...]
+//
+// Each file holds one sample document. If no file is given,
+// the sample is read from stdin. All samples are merged with
+// lzjson.InferSchema, then:
+//
+//   - object members missing in some samples, or being null,
+//     become pointers (slices and interface{} are left as-is);
+//   - numbers become int64 if they are all written as integers,
+//     otherwise float64;
+//   - nested objects become named struct types, named after
+//     the parent type and the member (e.g. RootAddress).
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-restit/lzjson"
+)
+
+// initialisms are words written in all capitals
+// when used in Go identifiers
+var initialisms = map[string]bool{
+	"API": true, "CSS": true, "DNS": true, "HTML": true,
+	"HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "TCP": true, "TLS": true,
+	"UI": true, "URI": true, "URL": true, "UUID": true,
+	"XML": true,
+}
+
+// goName converts a JSON key into an exported Go identifier
+func goName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var name strings.Builder
+	for _, word := range words {
+		// split camelCase words
+		start := 0
+		runes := []rune(word)
+		for i := 1; i <= len(runes); i++ {
+			if i < len(runes) && !(unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1])) {
+				continue
+			}
+			part := string(runes[start:i])
+			if upper := strings.ToUpper(part); initialisms[upper] {
+				name.WriteString(upper)
+			} else {
+				r := []rune(part)
+				name.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+			}
+			start = i
+		}
+	}
+	if name.Len() == 0 {
+		return "Field"
+	}
+	if str := name.String(); unicode.IsDigit([]rune(str)[0]) {
+		return "X" + str
+	}
+	return name.String()
+}
+
+// generator generates Go type definitions of shapes
+type generator struct {
+	names map[string]bool
+	decls []string
+}
+
+// typeName returns an unused type name based on the given name
+func (g *generator) typeName(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// goType returns the Go type expression of the shape. Object
+// shapes are declared as struct types named after the given name
+func (g *generator) goType(s *lzjson.Shape, name string) string {
+	if len(s.Types) != 1 {
+		return "interface{}"
+	}
+	switch s.Types[0] {
+	case lzjson.TypeString:
+		return "string"
+	case lzjson.TypeBool:
+		return "bool"
+	case lzjson.TypeNumber:
+		if s.Integer {
+			return "int64"
+		}
+		return "float64"
+	case lzjson.TypeArray:
+		if s.Items == nil {
+			return "[]interface{}"
+		}
+		return "[]" + g.goType(s.Items, name+"Item")
+	}
+	return g.structType(s, name)
+}
+
+// structType declares the struct type of the object shape
+// then returns its name
+func (g *generator) structType(s *lzjson.Shape, name string) string {
+	name = g.typeName(name)
+	i := len(g.decls)
+	g.decls = append(g.decls, "") // keep the declaration order
+
+	var decl bytes.Buffer
+	fmt.Fprintf(&decl, "type %s struct {\n", name)
+	fields := map[string]bool{}
+	for _, f := range s.Fields {
+		fieldName := goName(f.Name)
+		for j := 2; fields[fieldName]; j++ {
+			fieldName = goName(f.Name) + strconv.Itoa(j)
+		}
+		fields[fieldName] = true
+
+		typ := g.goType(f.Shape, name+fieldName)
+		if (f.Optional || f.Shape.Nullable) && !strings.HasPrefix(typ, "[]") && typ != "interface{}" {
+			typ = "*" + typ
+		}
+		tag := f.Name
+		if f.Optional {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&decl, "%s %s `json:%s`\n", fieldName, typ, strconv.Quote(tag))
+	}
+	decl.WriteString("}\n")
+	g.decls[i] = decl.String()
+	return name
+}
+
+// generate returns the formatted Go source of the types
+// inferred from the samples
+func generate(pkg, name string, samples []lzjson.Node) ([]byte, error) {
+	g := &generator{names: map[string]bool{}}
+	shape := lzjson.InferSchema(samples...)
+	if typ := g.goType(shape, name); typ != name {
+		// root is not a struct
+		g.decls = append([]string{fmt.Sprintf("type %s %s\n", name, typ)}, g.decls...)
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by lzjson-gen. DO NOT EDIT.\n\npackage %s\n", pkg)
+	for _, decl := range g.decls {
+		src.WriteString("\n" + decl)
+	}
+	return format.Source(src.Bytes())
+}
+
+// readSample reads and validates a JSON sample
+func readSample(r io.Reader) (lzjson.Node, error) {
+	n := lzjson.Decode(r)
+	if err := n.ParseError(); err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(n.Raw(), &v); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// run runs the command with the arguments (without the
+// program name) and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lzjson-gen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	name := flags.String("name", "Root", "name of the root type")
+	pkg := flags.String("package", "main", "name of the package")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if goName(*name) != *name {
+		fmt.Fprintf(stderr, "lzjson-gen: invalid type name %#v\n", *name)
+		return 2
+	}
+
+	samples := []lzjson.Node{}
+	if flags.NArg() == 0 {
+		n, err := readSample(stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "lzjson-gen: <stdin>: %s\n", err.Error())
+			return 1
+		}
+		samples = append(samples, n)
+	}
+	for _, filename := range flags.Args() {
+		f, err := os.Open(filename)
+		if err != nil {
+			fmt.Fprintf(stderr, "lzjson-gen: %s\n", err.Error())
+			return 1
+		}
+		n, err := readSample(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(stderr, "lzjson-gen: %s: %s\n", filename, err.Error())
+			return 1
+		}
+		samples = append(samples, n)
+	}
+
+	src, err := generate(*pkg, *name, samples)
+	if err != nil {
+		fmt.Fprintf(stderr, "lzjson-gen: %s\n", err.Error())
+		return 1
+	}
+	stdout.Write(src)
+	return 0
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
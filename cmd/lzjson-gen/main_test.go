@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoName(t *testing.T) {
+	tests := []struct {
+		Key  string
+		Name string
+	}{
+		{"name", "Name"},
+		{"user_id", "UserID"},
+		{"userId", "UserID"},
+		{"homepage-url", "HomepageURL"},
+		{"HTTPServer", "HTTPServer"},
+		{"2fa", "X2fa"},
+		{"", "Field"},
+		{"$", "Field"},
+	}
+	for _, test := range tests {
+		if want, have := test.Name, goName(test.Key); want != have {
+			t.Errorf("key=%#v expected %#v, got %#v", test.Key, want, have)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lzjson-gen")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	samples := []string{
+		`{"id": 1, "name": "John", "score": 4.5, "tags": ["a"], "address": {"city": "Hong Kong"}}`,
+		`{"id": 2, "name": null, "score": 3, "tags": [], "address": {"city": "Tokyo", "zip": "100"}, "friends": [{"id": 1}]}`,
+	}
+	args := []string{"-name", "User", "-package", "model"}
+	for i, sample := range samples {
+		filename := filepath.Join(dir, string(rune('a'+i))+".json")
+		if err := ioutil.WriteFile(filename, []byte(sample), 0644); err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+		args = append(args, filename)
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if want, have := 0, run(args, nil, stdout, stderr); want != have {
+		t.Fatalf("expected %d, got %d (%s)", want, have, stderr.String())
+	}
+	want := "// Code generated by lzjson-gen. DO NOT EDIT.\n\n" +
+		"package model\n\n" +
+		"type User struct {\n" +
+		"\tID      int64             `json:\"id\"`\n" +
+		"\tName    *string           `json:\"name\"`\n" +
+		"\tScore   float64           `json:\"score\"`\n" +
+		"\tTags    []string          `json:\"tags\"`\n" +
+		"\tAddress UserAddress       `json:\"address\"`\n" +
+		"\tFriends []UserFriendsItem `json:\"friends,omitempty\"`\n" +
+		"}\n\n" +
+		"type UserAddress struct {\n" +
+		"\tCity string  `json:\"city\"`\n" +
+		"\tZip  *string `json:\"zip,omitempty\"`\n" +
+		"}\n\n" +
+		"type UserFriendsItem struct {\n" +
+		"\tID int64 `json:\"id\"`\n" +
+		"}\n"
+	if have := stdout.String(); want != have {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, have)
+	}
+}
+
+func TestRun_stdin(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  string
+	}{
+		{`[{"a": true}]`, "type Root []RootItem\n\ntype RootItem struct {\n\tA bool `json:\"a\"`\n}\n"},
+		{`[1, "a"]`, "type Root []interface{}\n"},
+		{`{}`, "type Root struct {\n}\n"},
+	}
+	for _, test := range tests {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		if want, have := 0, run(nil, strings.NewReader(test.Input), stdout, stderr); want != have {
+			t.Errorf("input=%s expected %d, got %d (%s)", test.Input, want, have, stderr.String())
+			continue
+		}
+		want := "// Code generated by lzjson-gen. DO NOT EDIT.\n\npackage main\n\n" + test.Want
+		if have := stdout.String(); want != have {
+			t.Errorf("input=%s expected:\n%s\ngot:\n%s", test.Input, want, have)
+		}
+	}
+}
+
+func TestRun_error(t *testing.T) {
+	tests := []struct {
+		Args   []string
+		Input  string
+		Code   int
+		Stderr string
+	}{
+		{nil, `{"a": `, 1, "lzjson-gen: <stdin>: unexpected end of JSON input\n"},
+		{nil, ``, 1, "lzjson-gen: <stdin>: unexpected end of JSON input\n"},
+		{[]string{"-name", "root"}, `{}`, 2, "lzjson-gen: invalid type name \"root\"\n"},
+		{[]string{"-unknown"}, `{}`, 2, ""},
+	}
+	for _, test := range tests {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		if want, have := test.Code, run(test.Args, strings.NewReader(test.Input), stdout, stderr); want != have {
+			t.Errorf("args=%v expected %d, got %d", test.Args, want, have)
+		}
+		if want, have := test.Stderr, stderr.String(); want != "" && want != have {
+			t.Errorf("args=%v expected %#v, got %#v", test.Args, want, have)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Command lzjson queries JSON documents with selectors.
+//
+// Usage:
+//
+//	lzjson [flags] selector [file ...]
+//
+// The selector (e.g. `foo.bar[2]["hello world"]`) follows the
+// same grammar as Node.Set. An empty selector or "." selects the
+// whole document. If no file is given, or the file is "-", the
+// document is read from stdin. Flags:
+//
+//	-r       print strings without quotes
+//	-type    print the type of the value (e.g. TypeString)
+//	-keys    print the keys of the object, one per line
+//	-length  print the length of the array or string
+//	-ndjson  read newline-delimited JSON, one document per line
+//
+// The exit code is 0 on success, 1 if the value is undefined,
+// 2 on usage error, 3 if the value is not of the type required
+// by the selector or flags, and 4 if the input is not valid JSON
+// or cannot be read. With more than one document, the exit code
+// is of the first failing document.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/go-restit/lzjson"
+)
+
+// exit codes
+const (
+	exitOK = iota
+	exitUndefined
+	exitUsage
+	exitTypeMismatch
+	exitParseError
+)
+
+// options are the output options of the command
+type options struct {
+	raw    bool
+	typ    bool
+	keys   bool
+	length bool
+}
+
+// query selects the value from the document and prints the
+// result to stdout. Returns the exit code and error, if any
+func query(doc []byte, sel string, opts options, stdout io.Writer) (int, error) {
+	if !json.Valid(doc) {
+		var v interface{}
+		return exitParseError, json.Unmarshal(doc, &v)
+	}
+
+	n, _ := lzjson.Select(lzjson.Parse(bytes.TrimSpace(doc)), sel)
+	if err := n.ParseError(); err != nil {
+		if errors.Is(err, lzjson.ErrorUndefined) {
+			return exitUndefined, err
+		}
+		return exitTypeMismatch, err
+	}
+
+	switch {
+	case opts.typ:
+		fmt.Fprintln(stdout, n.Type().String())
+	case opts.keys:
+		if n.Type() != lzjson.TypeObject {
+			return exitTypeMismatch, fmt.Errorf("%s is not an object", n.Type())
+		}
+		keys := n.GetKeys()
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintln(stdout, key)
+		}
+	case opts.length:
+		l := n.Len()
+		if l < 0 {
+			return exitTypeMismatch, fmt.Errorf("%s has no length", n.Type())
+		}
+		fmt.Fprintln(stdout, l)
+	case opts.raw && n.Type() == lzjson.TypeString:
+		fmt.Fprintln(stdout, n.String())
+	default:
+		fmt.Fprintf(stdout, "%s\n", n.Raw())
+	}
+	return exitOK, nil
+}
+
+// queryReader queries every document read from r and
+// returns the exit code of the first failing one
+func queryReader(r io.Reader, name, sel string, ndjson bool, opts options, stdout, stderr io.Writer) int {
+	if !ndjson {
+		doc := lzjson.Decode(r)
+		if err := doc.ParseError(); err != nil {
+			fmt.Fprintf(stderr, "lzjson: %s: %s\n", name, err.Error())
+			return exitParseError
+		}
+		code, err := query(doc.Raw(), sel, opts, stdout)
+		if err != nil {
+			fmt.Fprintf(stderr, "lzjson: %s: %s\n", name, err.Error())
+		}
+		return code
+	}
+
+	code := exitOK
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if c, err := query(scanner.Bytes(), sel, opts, stdout); err != nil {
+			fmt.Fprintf(stderr, "lzjson: %s:%d: %s\n", name, line, err.Error())
+			if code == exitOK {
+				code = c
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(stderr, "lzjson: %s: %s\n", name, err.Error())
+		if code == exitOK {
+			code = exitParseError
+		}
+	}
+	return code
+}
+
+// run runs the command with the arguments (without the
+// program name) and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lzjson", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var opts options
+	flags.BoolVar(&opts.raw, "r", false, "print strings without quotes")
+	flags.BoolVar(&opts.typ, "type", false, "print the type of the value")
+	flags.BoolVar(&opts.keys, "keys", false, "print the keys of the object")
+	flags.BoolVar(&opts.length, "length", false, "print the length of the array or string")
+	ndjson := flags.Bool("ndjson", false, "read newline-delimited JSON")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "usage: lzjson [flags] selector [file ...]")
+		return exitUsage
+	}
+	sel := flags.Arg(0)
+	if sel == "." {
+		sel = ""
+	}
+	if _, err := lzjson.Select(lzjson.NewNode(), sel); err != nil {
+		fmt.Fprintf(stderr, "lzjson: %s\n", err.Error())
+		return exitUsage
+	}
+
+	files := flags.Args()[1:]
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	code := exitOK
+	for _, filename := range files {
+		var c int
+		if filename == "-" {
+			c = queryReader(stdin, "<stdin>", sel, *ndjson, opts, stdout, stderr)
+		} else if f, err := os.Open(filename); err != nil {
+			fmt.Fprintf(stderr, "lzjson: %s\n", err.Error())
+			c = exitParseError
+		} else {
+			c = queryReader(f, filename, sel, *ndjson, opts, stdout, stderr)
+			f.Close()
+		}
+		if code == exitOK {
+			code = c
+		}
+	}
+	return code
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
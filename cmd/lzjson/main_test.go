@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDoc = `{
+	"name": "John",
+	"age": 42,
+	"tags": ["a", "b"],
+	"address": {"city": "Hong Kong", "zip": null}
+}`
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		Args   []string
+		Stdout string
+	}{
+		{[]string{"name"}, "\"John\"\n"},
+		{[]string{"-r", "name"}, "John\n"},
+		{[]string{"-r", "age"}, "42\n"},
+		{[]string{"tags[1]"}, "\"b\"\n"},
+		{[]string{"address"}, "{\"city\": \"Hong Kong\", \"zip\": null}\n"},
+		{[]string{"-type", "address.zip"}, "TypeNull\n"},
+		{[]string{"-type", "."}, "TypeObject\n"},
+		{[]string{"-keys", "address"}, "city\nzip\n"},
+		{[]string{"-keys", ""}, "address\nage\nname\ntags\n"},
+		{[]string{"-length", "tags"}, "2\n"},
+		{[]string{"-length", "name"}, "4\n"},
+	}
+	for _, test := range tests {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		if want, have := exitOK, run(test.Args, strings.NewReader(testDoc), stdout, stderr); want != have {
+			t.Errorf("args=%v expected %d, got %d (%s)", test.Args, want, have, stderr.String())
+		}
+		if want, have := test.Stdout, stdout.String(); want != have {
+			t.Errorf("args=%v expected %#v, got %#v", test.Args, want, have)
+		}
+	}
+}
+
+func TestRun_error(t *testing.T) {
+	tests := []struct {
+		Args   []string
+		Input  string
+		Code   int
+		Stderr string
+	}{
+		{[]string{"email"}, testDoc, exitUndefined, "lzjson: <stdin>: json.email: undefined\n"},
+		{[]string{"tags[2]"}, testDoc, exitUndefined, "lzjson: <stdin>: json.tags[2]: index out of range\n"},
+		{[]string{"name.first"}, testDoc, exitTypeMismatch, "lzjson: <stdin>: json.name.first: type mismatch (json.name is not an object)\n"},
+		{[]string{"name[0]"}, testDoc, exitTypeMismatch, "lzjson: <stdin>: json.name[0]: type mismatch (json.name is not an array)\n"},
+		{[]string{"-keys", "tags"}, testDoc, exitTypeMismatch, "lzjson: <stdin>: TypeArray is not an object\n"},
+		{[]string{"-length", "age"}, testDoc, exitTypeMismatch, "lzjson: <stdin>: TypeNumber has no length\n"},
+		{[]string{"name"}, `{"name": `, exitParseError, "lzjson: <stdin>: unexpected end of JSON input\n"},
+		{[]string{"name"}, ``, exitParseError, "lzjson: <stdin>: unexpected end of JSON input\n"},
+		{[]string{"name["}, testDoc, exitUsage, ""},
+		{[]string{}, testDoc, exitUsage, "usage: lzjson [flags] selector [file ...]\n"},
+		{[]string{"-unknown", "name"}, testDoc, exitUsage, ""},
+	}
+	for _, test := range tests {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		if want, have := test.Code, run(test.Args, strings.NewReader(test.Input), stdout, stderr); want != have {
+			t.Errorf("args=%v expected %d, got %d (%s)", test.Args, want, have, stderr.String())
+		}
+		if want, have := test.Stderr, stderr.String(); want != "" && want != have {
+			t.Errorf("args=%v expected %#v, got %#v", test.Args, want, have)
+		}
+		if want, have := "", stdout.String(); want != have {
+			t.Errorf("args=%v expected %#v, got %#v", test.Args, want, have)
+		}
+	}
+}
+
+func TestRun_ndjson(t *testing.T) {
+	input := "{\"id\": 1, \"name\": \"a\"}\n\n{\"id\": 2}\n{\"id\": 3, \"name\": \"c\"}\n{\"id\": \n"
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if want, have := exitUndefined, run([]string{"-ndjson", "-r", "name"}, strings.NewReader(input), stdout, stderr); want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+	if want, have := "a\nc\n", stdout.String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	wantErr := "lzjson: <stdin>:3: json.name: undefined\n" +
+		"lzjson: <stdin>:5: unexpected end of JSON input\n"
+	if want, have := wantErr, stderr.String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
+
+func TestRun_files(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lzjson")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	a, b := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")
+	ioutil.WriteFile(a, []byte(`{"id": 1}`), 0644)
+	ioutil.WriteFile(b, []byte(`{"id": 2}`), 0644)
+	missing := filepath.Join(dir, "missing.json")
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if want, have := exitParseError, run([]string{"id", a, missing, "-", b}, strings.NewReader(`{"id": "x"}`), stdout, stderr); want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+	if want, have := "1\n\"x\"\n2\n", stdout.String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if !strings.Contains(stderr.String(), "missing.json") {
+		t.Errorf("expected error of the missing file, got %#v", stderr.String())
+	}
+}
@@ -0,0 +1,192 @@
+package lzjson
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+)
+
+// csvConfig holds the options of WriteCSV
+type csvConfig struct {
+	columns  []string
+	comma    rune
+	noHeader bool
+}
+
+// CSVOption configures WriteCSV
+type CSVOption func(*csvConfig)
+
+// CSVColumns sets the columns to the values at the selectors
+// (e.g. `user.name`, `tags[0]`) of each item, in the given
+// order. The selectors are the names in the header row
+func CSVColumns(selectors ...string) CSVOption {
+	return func(c *csvConfig) {
+		c.columns = selectors
+	}
+}
+
+// CSVComma sets the field delimiter (e.g. '\t' for TSV).
+// The default is ','
+func CSVComma(r rune) CSVOption {
+	return func(c *csvConfig) {
+		c.comma = r
+	}
+}
+
+// CSVNoHeader omits the header row
+func CSVNoHeader() CSVOption {
+	return func(c *csvConfig) {
+		c.noHeader = true
+	}
+}
+
+// WriteCSV writes the items of the array node as CSV rows,
+// with a header row of the column names.
+//
+// Without the CSVColumns option, every item must be an object.
+// The columns are the union of the keys of the items, in the
+// order first seen. Nested objects are flattened into dotted
+// column names (e.g. `user.name`).
+//
+// Strings are written without quotes, null and missing values
+// as empty cells, and arrays (or objects at a selector) as
+// compact JSON. Rows are written as the items are read, so
+// a failed write may leave some rows written.
+func WriteCSV(w io.Writer, n Node, opts ...CSVOption) error {
+	c := csvConfig{comma: ','}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	b, err := definedValue(n)
+	if err != nil {
+		return err
+	}
+	path := nodePath(n)
+	if b[0] != '[' {
+		return Error{Path: "json" + path, Err: ErrorNotArray}
+	}
+	elems, _, _ := scanArray(b, 0)
+
+	var row func(item []byte) []string
+	columns := c.columns
+	if columns == nil {
+		if columns, err = csvColumns(b, elems, path); err != nil {
+			return err
+		}
+		row = csvFlatRow(columns)
+	} else {
+		steps := make([][]selStep, len(columns))
+		for i, sel := range columns {
+			if steps[i], err = parseSel(sel); err != nil {
+				return err
+			}
+		}
+		row = csvSelectRow(steps)
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = c.comma
+	if !c.noHeader {
+		if err := cw.Write(columns); err != nil {
+			return err
+		}
+	}
+	for _, e := range elems {
+		if err := cw.Write(row(b[e.start:e.end])); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvColumns returns the union of the flattened keys of
+// the objects of the array, in the order first seen
+func csvColumns(b []byte, elems []arrElem, path string) ([]string, error) {
+	columns := []string{}
+	seen := map[string]bool{}
+	for i, e := range elems {
+		item := b[e.start:e.end]
+		if item[0] != '{' {
+			return nil, Error{Path: "json" + nthPath(path, i), Err: ErrorNotObject}
+		}
+		flattenCSV(item, "", func(col string, v []byte) {
+			if !seen[col] {
+				seen[col] = true
+				columns = append(columns, col)
+			}
+		})
+	}
+	return columns, nil
+}
+
+// flattenCSV visits the members of the validated raw JSON
+// object. Members of nested non-empty objects are visited
+// with dotted names instead of the objects
+func flattenCSV(b []byte, prefix string, visit func(col string, v []byte)) {
+	members, _, _ := scanObject(b, 0)
+	for i, m := range members {
+		if lastMember(members, m.key) != i {
+			continue // overridden by duplicated key
+		}
+		col, v := m.key, b[m.valStart:m.valEnd]
+		if prefix != "" {
+			col = prefix + "." + m.key
+		}
+		if v[0] == '{' {
+			if nested, _, _ := scanObject(v, 0); len(nested) > 0 {
+				flattenCSV(v, col, visit)
+				continue
+			}
+		}
+		visit(col, v)
+	}
+}
+
+// csvFlatRow returns a function to get the cells
+// of an object item by the flattened columns
+func csvFlatRow(columns []string) func(item []byte) []string {
+	return func(item []byte) []string {
+		values := map[string][]byte{}
+		flattenCSV(item, "", func(col string, v []byte) {
+			values[col] = v
+		})
+		cells := make([]string, len(columns))
+		for i, col := range columns {
+			cells[i] = csvCell(values[col])
+		}
+		return cells
+	}
+}
+
+// csvSelectRow returns a function to get the cells
+// of an item by the steps of the parsed selectors
+func csvSelectRow(steps [][]selStep) func(item []byte) []string {
+	return func(item []byte) []string {
+		cells := make([]string, len(steps))
+		for i, s := range steps {
+			if start, end, _, err := locate(item, 0, "", s); err == nil {
+				cells[i] = csvCell(item[start:end])
+			}
+		}
+		return cells
+	}
+}
+
+// csvCell returns the cell text of the validated raw JSON
+// value. Empty for null or nil (i.e. missing)
+func csvCell(v []byte) string {
+	switch {
+	case len(v) == 0 || v[0] == 'n':
+		return ""
+	case v[0] == '"':
+		return unquote(v)
+	case v[0] == '{' || v[0] == '[':
+		var buf bytes.Buffer
+		json.Compact(&buf, v)
+		return buf.String()
+	}
+	return string(v)
+}
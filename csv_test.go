@@ -0,0 +1,111 @@
+package lzjson_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+const csvData = `[
+	{"id": 1, "name": "Alice", "address": {"city": "Paris", "geo": {"lat": 48.85}}, "tags": ["a", "b"]},
+	{"id": 2, "name": "Bob, \"Jr\"", "email": "bob@example.com", "address": {"city": "Oslo"}, "active": true},
+	{"id": 3, "name": "multi\nline", "address": null, "meta": {}}
+]`
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := lzjson.WriteCSV(&buf, lzjson.ParseString(csvData)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := `id,name,address.city,address.geo.lat,tags,email,active,address,meta
+1,Alice,Paris,48.85,"[""a"",""b""]",,,,
+2,"Bob, ""Jr""",Oslo,,,bob@example.com,true,,
+3,"multi
+line",,,,,,,{}
+`
+	if want, have := want, buf.String(); want != have {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, have)
+	}
+}
+
+func TestWriteCSV_options(t *testing.T) {
+	var buf bytes.Buffer
+	err := lzjson.WriteCSV(&buf, lzjson.ParseString(csvData),
+		lzjson.CSVColumns("name", "address.city", "tags[1]", "address", "missing"),
+		lzjson.CSVComma('\t'),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := "name\taddress.city\ttags[1]\taddress\tmissing\n" +
+		"Alice\tParis\tb\t\"{\"\"city\"\":\"\"Paris\"\",\"\"geo\"\":{\"\"lat\"\":48.85}}\"\t\n" +
+		"\"Bob, \"\"Jr\"\"\"\tOslo\t\t\"{\"\"city\"\":\"\"Oslo\"\"}\"\t\n" +
+		"\"multi\nline\"\t\t\t\t\n"
+	if want, have := want, buf.String(); want != have {
+		t.Errorf("expected:\n%q\ngot:\n%q", want, have)
+	}
+
+	// selectors work on arrays of arrays as well
+	buf.Reset()
+	err = lzjson.WriteCSV(&buf, lzjson.ParseString(`[[1, "x"], [2]]`), lzjson.CSVColumns("[0]", "[1]"), lzjson.CSVNoHeader())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want, have := "1,x\n2,\n", buf.String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+
+	// an empty array has only the header
+	buf.Reset()
+	if err := lzjson.WriteCSV(&buf, lzjson.ParseString(`[]`), lzjson.CSVColumns("a", "b")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want, have := "a,b\n", buf.String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
+
+func TestWriteCSV_error(t *testing.T) {
+	root := lzjson.ParseString(`{"list": [{"a": 1}, 2], "obj": {}}`)
+	tests := []struct {
+		node lzjson.Node
+		opts []lzjson.CSVOption
+		err  string
+		kind error
+	}{
+		{root.Get("obj"), nil, "json.obj: not an array", lzjson.ErrorNotArray},
+		{root.Get("list"), nil, "json.list[1]: not an object", lzjson.ErrorNotObject},
+		{root.Get("missing"), nil, "json.missing: undefined", lzjson.ErrorUndefined},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		err := lzjson.WriteCSV(&buf, test.node, test.opts...)
+		if err == nil {
+			t.Errorf("expected error %#v, got nil", test.err)
+			continue
+		}
+		if want, have := test.err, err.Error(); want != have {
+			t.Errorf("expected %#v, got %#v", want, have)
+		}
+		if !errors.Is(err, test.kind) {
+			t.Errorf("expected %#v, got %#v", test.kind, err)
+		}
+		if want, have := 0, buf.Len(); want != have {
+			t.Errorf("expected nothing written, got %#v", buf.String())
+		}
+	}
+
+	// invalid selector or delimiter
+	var buf bytes.Buffer
+	if err := lzjson.WriteCSV(&buf, root.Get("list"), lzjson.CSVColumns("a[")); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if err := lzjson.WriteCSV(&buf, lzjson.ParseString(`[]`), lzjson.CSVComma('"')); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if want, have := 0, buf.Len(); want != have {
+		t.Errorf("expected nothing written, got %#v", buf.String())
+	}
+}
@@ -0,0 +1,275 @@
+package lzjson
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ChangeType represents the nature of a Change
+type ChangeType int
+
+// types of change
+const (
+	ChangeAdded ChangeType = iota
+	ChangeRemoved
+	ChangeModified
+)
+
+// String implements Stringer
+func (typ ChangeType) String() string {
+	switch typ {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	}
+	return fmt.Sprintf("ChangeType(%d)", int(typ))
+}
+
+// Change describes a difference between 2 JSON values
+type Change struct {
+	Type ChangeType
+	Path string // path of the value, in the same format as Error
+	Old  []byte // raw value before the change, nil if added
+	New  []byte // raw value after the change, nil if removed
+}
+
+// String implements Stringer
+func (c Change) String() string {
+	switch c.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("%s: added %s", c.Path, compactRaw(c.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("%s: removed %s", c.Path, compactRaw(c.Old))
+	}
+	return fmt.Sprintf("%s: %s => %s", c.Path, compactRaw(c.Old), compactRaw(c.New))
+}
+
+// arrayMode is how arrays are compared in Diff
+type arrayMode int
+
+const (
+	arrayByIndex arrayMode = iota
+	arrayByKey
+	arrayUnordered
+)
+
+// diffConfig holds the options of Diff
+type diffConfig struct {
+	arrays arrayMode
+	key    string
+}
+
+// DiffOption configures Diff
+type DiffOption func(*diffConfig)
+
+// DiffArrayByIndex compares arrays item by item at the same
+// index. This is the default behaviour
+func DiffArrayByIndex() DiffOption {
+	return func(c *diffConfig) {
+		c.arrays = arrayByIndex
+	}
+}
+
+// DiffArrayByKey compares arrays of objects by matching items
+// with the same value of the given field. Items without
+// the field only match identical items
+func DiffArrayByKey(field string) DiffOption {
+	return func(c *diffConfig) {
+		c.arrays = arrayByKey
+		c.key = field
+	}
+}
+
+// DiffArrayUnordered compares arrays as unordered collections.
+// Items are either found in both arrays, added or removed
+func DiffArrayUnordered() DiffOption {
+	return func(c *diffConfig) {
+		c.arrays = arrayUnordered
+	}
+}
+
+// Diff returns the changes that turn a into b, in document order.
+// Undefined or error nodes are treated as absent values. If either
+// raw JSON is malformed, the values are not compared member by
+// member but reported as a change of the whole value
+func Diff(a, b Node, opts ...DiffOption) []Change {
+	c := diffConfig{}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	ra, aBad := diffValue(a)
+	rb, bBad := diffValue(b)
+
+	changes := []Change{}
+	switch {
+	case len(ra) == 0 && len(rb) == 0:
+	case len(ra) == 0:
+		changes = append(changes, Change{Type: ChangeAdded, Path: "json", New: rb})
+	case len(rb) == 0:
+		changes = append(changes, Change{Type: ChangeRemoved, Path: "json", Old: ra})
+	case aBad || bBad:
+		if !bytes.Equal(ra, rb) {
+			changes = append(changes, Change{Type: ChangeModified, Path: "json", Old: ra, New: rb})
+		}
+	default:
+		c.diff(ra, rb, "", &changes)
+	}
+	return changes
+}
+
+// diffValue returns the raw value of the node to diff, and if
+// it is malformed. Undefined or error nodes have no raw value
+func diffValue(n Node) (b []byte, malformed bool) {
+	if n.ParseError() != nil {
+		return nil, false
+	}
+	if b, err := rawValue(n); err == nil {
+		return b, false
+	}
+	return bytes.TrimSpace(n.Raw()), true
+}
+
+// diff appends the changes between 2 validated raw JSON values
+func (c diffConfig) diff(a, b []byte, path string, changes *[]Change) {
+	switch {
+	case a[0] == '{' && b[0] == '{':
+		c.diffObject(a, b, path, changes)
+	case a[0] == '[' && b[0] == '[':
+		switch c.arrays {
+		case arrayByKey:
+			c.diffArrayByKey(a, b, path, changes)
+		case arrayUnordered:
+			c.diffArrayUnordered(a, b, path, changes)
+		default:
+			c.diffArrayByIndex(a, b, path, changes)
+		}
+	case !bytes.Equal(a, b) && !jsonEqual(a, b):
+		*changes = append(*changes, Change{Type: ChangeModified, Path: "json" + path, Old: a, New: b})
+	}
+}
+
+func (c diffConfig) diffObject(a, b []byte, path string, changes *[]Change) {
+	am, _, _ := scanObject(a, 0)
+	bm, _, _ := scanObject(b, 0)
+	for i, m := range am {
+		if lastMember(am, m.key) != i {
+			continue // overridden by duplicated key
+		}
+		va := a[m.valStart:m.valEnd]
+		if j := lastMember(bm, m.key); j < 0 {
+			*changes = append(*changes, Change{Type: ChangeRemoved, Path: "json" + keyPath(path, m.key), Old: va})
+		} else {
+			c.diff(va, b[bm[j].valStart:bm[j].valEnd], keyPath(path, m.key), changes)
+		}
+	}
+	for i, m := range bm {
+		if lastMember(bm, m.key) == i && lastMember(am, m.key) < 0 {
+			*changes = append(*changes, Change{Type: ChangeAdded, Path: "json" + keyPath(path, m.key), New: b[m.valStart:m.valEnd]})
+		}
+	}
+}
+
+func (c diffConfig) diffArrayByIndex(a, b []byte, path string, changes *[]Change) {
+	ae, _, _ := scanArray(a, 0)
+	be, _, _ := scanArray(b, 0)
+	for i := 0; i < len(ae) || i < len(be); i++ {
+		switch {
+		case i >= len(be):
+			*changes = append(*changes, Change{Type: ChangeRemoved, Path: "json" + nthPath(path, i), Old: a[ae[i].start:ae[i].end]})
+		case i >= len(ae):
+			*changes = append(*changes, Change{Type: ChangeAdded, Path: "json" + nthPath(path, i), New: b[be[i].start:be[i].end]})
+		default:
+			c.diff(a[ae[i].start:ae[i].end], b[be[i].start:be[i].end], nthPath(path, i), changes)
+		}
+	}
+}
+
+// itemKey returns the key to match an array item in DiffArrayByKey mode
+func (c diffConfig) itemKey(v []byte) string {
+	if v[0] == '{' {
+		members, _, _ := scanObject(v, 0)
+		if i := lastMember(members, c.key); i >= 0 {
+			return "key:" + string(compactRaw(v[members[i].valStart:members[i].valEnd]))
+		}
+	}
+	return "raw:" + string(compactRaw(v))
+}
+
+func (c diffConfig) diffArrayByKey(a, b []byte, path string, changes *[]Change) {
+	ae, _, _ := scanArray(a, 0)
+	be, _, _ := scanArray(b, 0)
+
+	// index the items in b by key, first come first match
+	bIndex := map[string][]int{}
+	for j, e := range be {
+		k := c.itemKey(b[e.start:e.end])
+		bIndex[k] = append(bIndex[k], j)
+	}
+	matched := make([]bool, len(be))
+	for i, e := range ae {
+		va := a[e.start:e.end]
+		k := c.itemKey(va)
+		if len(bIndex[k]) == 0 {
+			*changes = append(*changes, Change{Type: ChangeRemoved, Path: "json" + nthPath(path, i), Old: va})
+			continue
+		}
+		j := bIndex[k][0]
+		bIndex[k] = bIndex[k][1:]
+		matched[j] = true
+		c.diff(va, b[be[j].start:be[j].end], nthPath(path, j), changes)
+	}
+	for j, e := range be {
+		if !matched[j] {
+			*changes = append(*changes, Change{Type: ChangeAdded, Path: "json" + nthPath(path, j), New: b[e.start:e.end]})
+		}
+	}
+}
+
+func (c diffConfig) diffArrayUnordered(a, b []byte, path string, changes *[]Change) {
+	ae, _, _ := scanArray(a, 0)
+	be, _, _ := scanArray(b, 0)
+	matched := make([]bool, len(be))
+	for i, e := range ae {
+		va := a[e.start:e.end]
+		found := false
+		for j, f := range be {
+			if !matched[j] && jsonEqual(va, b[f.start:f.end]) {
+				matched[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			*changes = append(*changes, Change{Type: ChangeRemoved, Path: "json" + nthPath(path, i), Old: va})
+		}
+	}
+	for j, e := range be {
+		if !matched[j] {
+			*changes = append(*changes, Change{Type: ChangeAdded, Path: "json" + nthPath(path, j), New: b[e.start:e.end]})
+		}
+	}
+}
+
+// Report formats the changes as a human-readable report in a
+// style similar to unified diff. Each change is headed by its
+// path, followed by the removed (-) and added (+) raw values.
+// Returns empty string if there is no change
+func Report(changes []Change) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	buf.WriteString("--- a\n+++ b\n")
+	for _, c := range changes {
+		fmt.Fprintf(&buf, "@@ %s (%s) @@\n", c.Path, c.Type)
+		if c.Old != nil {
+			fmt.Fprintf(&buf, "-%s\n", compactRaw(c.Old))
+		}
+		if c.New != nil {
+			fmt.Fprintf(&buf, "+%s\n", compactRaw(c.New))
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,109 @@
+package lzjson_test
+
+import (
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+func assertChanges(t *testing.T, name string, expected []string, changes []lzjson.Change) {
+	if want, have := len(expected), len(changes); want != have {
+		t.Errorf("%s: expected %d changes, got %d: %v", name, want, have, changes)
+		return
+	}
+	for i := range expected {
+		if want, have := expected[i], changes[i].String(); want != have {
+			t.Errorf("%s: changes[%d] expected %#v, got %#v", name, i, want, have)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := lzjson.ParseString(`{
+		"name": "foo",
+		"count": 1.0,
+		"tags": ["a", "b", "c"],
+		"owner": {"id": 1, "name": "John"},
+		"removed": true
+	}`)
+	b := lzjson.ParseString(`{"owner":{"name":"Jane","id":1},"count":1,"name":"bar","tags":["a","c"],"added":null}`)
+
+	assertChanges(t, "by index", []string{
+		`json.name: "foo" => "bar"`,
+		`json.tags[1]: "b" => "c"`,
+		`json.tags[2]: removed "c"`,
+		`json.owner.name: "John" => "Jane"`,
+		`json.removed: removed true`,
+		`json.added: added null`,
+	}, lzjson.Diff(a, b))
+
+	assertChanges(t, "unordered", []string{
+		`json.name: "foo" => "bar"`,
+		`json.tags[1]: removed "b"`,
+		`json.owner.name: "John" => "Jane"`,
+		`json.removed: removed true`,
+		`json.added: added null`,
+	}, lzjson.Diff(a, b, lzjson.DiffArrayUnordered()))
+
+	if changes := lzjson.Diff(a, a); len(changes) != 0 {
+		t.Errorf("expected no change, got %v", changes)
+	}
+}
+
+func TestDiff_arrayByKey(t *testing.T) {
+	a := lzjson.ParseString(`[
+		{"id": 1, "name": "one"},
+		{"id": 2, "name": "two"},
+		{"id": 3, "name": "three"},
+		"plain"
+	]`)
+	b := lzjson.ParseString(`[
+		{"id": 3, "name": "three"},
+		{"id": 1, "name": "uno"},
+		{"id": 4, "name": "four"},
+		"plain"
+	]`)
+	assertChanges(t, "by key", []string{
+		`json[1].name: "one" => "uno"`,
+		`json[1]: removed {"id":2,"name":"two"}`,
+		`json[2]: added {"id":4,"name":"four"}`,
+	}, lzjson.Diff(a, b, lzjson.DiffArrayByKey("id")))
+}
+
+func TestDiff_undefined(t *testing.T) {
+	root := lzjson.ParseString(`{"a": 1}`)
+	assertChanges(t, "added", []string{`json: added {"a":1}`}, lzjson.Diff(root.Get("b"), root))
+	assertChanges(t, "removed", []string{`json: removed {"a":1}`}, lzjson.Diff(root, lzjson.NewNode()))
+	assertChanges(t, "type changed", []string{`json.a: 1 => "1"`}, lzjson.Diff(root, lzjson.ParseString(`{"a": "1"}`)))
+}
+
+func TestDiff_malformed(t *testing.T) {
+	bad := lzjson.ParseString(`{bad`)
+	assertChanges(t, "both malformed", []string{`json: {bad => [1,2`},
+		lzjson.Diff(bad, lzjson.ParseString(`[1,2`)))
+	assertChanges(t, "malformed after", []string{`json: {"a":1} => {"a":1,}`},
+		lzjson.Diff(lzjson.ParseString(`{"a":1}`), lzjson.ParseString(`{"a":1,}`)))
+	assertChanges(t, "malformed added", []string{`json: added {bad`}, lzjson.Diff(lzjson.NewNode(), bad))
+	assertChanges(t, "same malformed", []string{}, lzjson.Diff(bad, lzjson.ParseString(` {bad `)))
+}
+
+func TestReport(t *testing.T) {
+	a := lzjson.ParseString(`{"a": 1, "b": [1, 2]}`)
+	b := lzjson.ParseString(`{"a": 2, "b": [1], "c": {"d": "e"}}`)
+	expected := `--- a
++++ b
+@@ json.a (modified) @@
+-1
++2
+@@ json.b[1] (removed) @@
+-2
+@@ json.c (added) @@
++{"d":"e"}
+`
+	if want, have := expected, lzjson.Report(lzjson.Diff(a, b)); want != have {
+		t.Errorf("\nexpected:\n%s\ngot:\n%s", want, have)
+	}
+	if want, have := "", lzjson.Report(lzjson.Diff(a, a)); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
@@ -0,0 +1,301 @@
+package lzjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// valueBytes returns the raw JSON of a value to be
+// written into a Node
+func valueBytes(v interface{}) ([]byte, error) {
+	if n, ok := v.(Node); ok {
+		b, err := n.MarshalJSON()
+		return bytes.TrimSpace(b), err
+	}
+	return json.Marshal(v)
+}
+
+// splice returns a new slice of b with b[start:end]
+// replaced by the given bytes
+func splice(b []byte, start, end int, with ...[]byte) []byte {
+	l := len(b) - (end - start)
+	for _, w := range with {
+		l += len(w)
+	}
+	out := make([]byte, 0, l)
+	out = append(out, b[:start]...)
+	for _, w := range with {
+		out = append(out, w...)
+	}
+	return append(out, b[end:]...)
+}
+
+// locate walks the steps from the value starting at b[start],
+// then returns the offsets and the path of the selected value
+func locate(b []byte, start int, path string, steps []selStep) (vstart, vend int, vpath string, err error) {
+	vstart, vpath = start, path
+	if vend, err = scanValue(b, vstart); err != nil {
+		err = Error{Path: "json" + vpath, Err: err}
+		return
+	}
+	for _, step := range steps {
+		if step.isIndex {
+			if b[vstart] != '[' {
+				err = Error{Path: "json" + vpath, Err: ErrorNotArray}
+				return
+			}
+			elems, _, _ := scanArray(b, vstart)
+			vpath = step.path(vpath)
+			if step.nth >= len(elems) {
+				err = Error{Path: "json" + vpath, Err: ErrorIndexOutOfRange}
+				return
+			}
+			vstart, vend = elems[step.nth].start, elems[step.nth].end
+			continue
+		}
+		if b[vstart] != '{' {
+			err = Error{Path: "json" + vpath, Err: ErrorNotObject}
+			return
+		}
+		members, _, _ := scanObject(b, vstart)
+		vpath = step.path(vpath)
+		i := lastMember(members, step.key)
+		if i < 0 {
+			err = Error{Path: "json" + vpath, Err: ErrorUndefined}
+			return
+		}
+		vstart, vend = members[i].valStart, members[i].valEnd
+	}
+	return
+}
+
+// editFunc returns a modified copy of b with the value in
+// b[start:end], at the given path, modified
+type editFunc func(b []byte, start, end int, path string) ([]byte, error)
+
+// edit locates the value selected by the steps then
+// returns a new Node with it modified by fn
+func (n *rootNode) edit(steps []selStep, fn editFunc) (Node, error) {
+	// locate scans only the selected values, so check the
+	// whole raw JSON before editing it. Non-finite numbers of
+	// JSON5 input are kept for the policy of the edited node
+	if _, err := rawValue(n); err != nil && !errors.Is(err, ErrorNonFinite) {
+		if _, ok := err.(Error); !ok {
+			err = Error{Path: "json" + n.path, Err: err}
+		}
+		return nil, err
+	}
+	start, end, path, err := locate(n.buf, skipSpace(n.buf, 0), n.path, steps)
+	if err != nil {
+		return nil, err
+	}
+	b, err := fn(n.buf, start, end, path)
+	if err != nil {
+		return nil, err
+	}
+	edited := &rootNode{
+		path: n.path,
+		buf:  b,
+		obj:  &objectCache{},
+	}
+	if n.json5() {
+		edited.limits = &Options{Syntax: SyntaxJSON5, NonFinite: n.limits.NonFinite}
+	}
+	return edited, nil
+}
+
+// parseParentSel parses the selector and splits the
+// last step from the steps to its parent
+func parseParentSel(sel string) (parent []selStep, last selStep, err error) {
+	steps, err := parseSel(sel)
+	if err != nil {
+		return
+	}
+	if len(steps) == 0 {
+		err = fmt.Errorf("invalid selector %#v: refers to no child value", sel)
+		return
+	}
+	return steps[:len(steps)-1], steps[len(steps)-1], nil
+}
+
+// setMember returns a copy of b with the key of the object at
+// b[start] set to v. If the key does not exist, it is added to the
+// end of the object following the formatting of existing members
+func setMember(b []byte, start int, key string, v []byte) []byte {
+	members, _, _ := scanObject(b, start)
+	if i := lastMember(members, key); i >= 0 {
+		return splice(b, members[i].valStart, members[i].valEnd, v)
+	}
+	k, _ := json.Marshal(key)
+	if len(members) == 0 {
+		return splice(b, start+1, start+1, k, []byte(":"), v)
+	}
+	m := members[len(members)-1]
+	colon := b[m.keyEnd:m.valStart]
+	var sep []byte
+	switch ws := b[start+1 : members[0].keyStart]; {
+	case len(members) > 1:
+		sep = b[members[len(members)-2].valEnd:m.keyStart]
+	case bytes.IndexByte(ws, '\n') >= 0:
+		sep = append([]byte{','}, ws...)
+	case bytes.IndexByte(colon, ' ') >= 0:
+		sep = []byte(", ")
+	default:
+		sep = []byte(",")
+	}
+	return splice(b, m.valEnd, m.valEnd, sep, k, colon, v)
+}
+
+// deleteMember returns a copy of b with every occurrence of the
+// key removed from the object at b[start], so that a duplicated
+// key would not resurface
+func deleteMember(b []byte, start int, key string) []byte {
+	members, end, _ := scanObject(b, start)
+	for i := lastMember(members, key); i >= 0; i = lastMember(members, key) {
+		switch {
+		case len(members) == 1:
+			b = splice(b, start+1, end-1)
+		case i < len(members)-1:
+			b = splice(b, members[i].keyStart, members[i+1].keyStart)
+		default:
+			b = splice(b, members[i-1].valEnd, members[i].valEnd)
+		}
+		members, end, _ = scanObject(b, start)
+	}
+	return b
+}
+
+// setElem returns a copy of b with the nth item of the
+// array at b[start] replaced by v
+func setElem(b []byte, start, nth int, v []byte) []byte {
+	elems, _, _ := scanArray(b, start)
+	return splice(b, elems[nth].start, elems[nth].end, v)
+}
+
+// deleteElem returns a copy of b with the nth item of the
+// array at b[start] removed
+func deleteElem(b []byte, start, nth int) []byte {
+	elems, end, _ := scanArray(b, start)
+	switch {
+	case len(elems) == 1:
+		return splice(b, start+1, end-1)
+	case nth < len(elems)-1:
+		return splice(b, elems[nth].start, elems[nth+1].start)
+	}
+	return splice(b, elems[nth-1].end, elems[nth].end)
+}
+
+// insertElem returns a copy of b with v inserted before the
+// nth item of the array at b[start], following the formatting
+// of existing items. If nth equals the array length, v is appended
+func insertElem(b []byte, start, nth int, v []byte) []byte {
+	elems, _, _ := scanArray(b, start)
+	if len(elems) == 0 {
+		return splice(b, start+1, start+1, v)
+	}
+	var sep []byte
+	switch ws := b[start+1 : elems[0].start]; {
+	case len(elems) > 1:
+		sep = b[elems[0].end:elems[1].start]
+	case bytes.IndexByte(ws, '\n') >= 0:
+		sep = append([]byte{','}, ws...)
+	case len(ws) > 0:
+		sep = []byte(", ")
+	default:
+		sep = []byte(",")
+	}
+	if nth == len(elems) {
+		return splice(b, elems[nth-1].end, elems[nth-1].end, sep, v)
+	}
+	return splice(b, elems[nth].start, elems[nth].start, v, sep)
+}
+
+// arrayLen returns the number of items in the array at b[start]
+func arrayLen(b []byte, start int) int {
+	elems, _, _ := scanArray(b, start)
+	return len(elems)
+}
+
+// hasMember tells if the object at b[start] has the key
+func hasMember(b []byte, start int, key string) bool {
+	members, _, _ := scanObject(b, start)
+	return lastMember(members, key) >= 0
+}
+
+// Set implements Node
+func (n *rootNode) Set(sel string, value interface{}) (Node, error) {
+	v, err := valueBytes(value)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := parseSel(sel)
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) == 0 {
+		// replace the whole value
+		return n.edit(steps, func(b []byte, start, end int, path string) ([]byte, error) {
+			return splice(b, start, end, v), nil
+		})
+	}
+	last := steps[len(steps)-1]
+	return n.edit(steps[:len(steps)-1], func(b []byte, start, end int, path string) ([]byte, error) {
+		switch {
+		case last.isIndex && b[start] != '[':
+			return nil, Error{Path: "json" + path, Err: ErrorNotArray}
+		case last.isIndex && last.nth >= arrayLen(b, start):
+			return nil, Error{Path: "json" + last.path(path), Err: ErrorIndexOutOfRange}
+		case last.isIndex:
+			return setElem(b, start, last.nth, v), nil
+		case b[start] != '{':
+			return nil, Error{Path: "json" + path, Err: ErrorNotObject}
+		}
+		return setMember(b, start, last.key, v), nil
+	})
+}
+
+// Delete implements Node
+func (n *rootNode) Delete(sel string) (Node, error) {
+	parent, last, err := parseParentSel(sel)
+	if err != nil {
+		return nil, err
+	}
+	return n.edit(parent, func(b []byte, start, end int, path string) ([]byte, error) {
+		switch {
+		case last.isIndex && b[start] != '[':
+			return nil, Error{Path: "json" + path, Err: ErrorNotArray}
+		case last.isIndex && last.nth >= arrayLen(b, start):
+			return nil, Error{Path: "json" + last.path(path), Err: ErrorIndexOutOfRange}
+		case last.isIndex:
+			return deleteElem(b, start, last.nth), nil
+		case b[start] != '{':
+			return nil, Error{Path: "json" + path, Err: ErrorNotObject}
+		case !hasMember(b, start, last.key):
+			return nil, Error{Path: "json" + last.path(path), Err: ErrorUndefined}
+		}
+		return deleteMember(b, start, last.key), nil
+	})
+}
+
+// Insert implements Node
+func (n *rootNode) Insert(sel string, nth int, value interface{}) (Node, error) {
+	v, err := valueBytes(value)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := parseSel(sel)
+	if err != nil {
+		return nil, err
+	}
+	return n.edit(steps, func(b []byte, start, end int, path string) ([]byte, error) {
+		switch {
+		case b[start] != '[':
+			return nil, Error{Path: "json" + path, Err: ErrorNotArray}
+		case nth < 0 || nth > arrayLen(b, start):
+			return nil, Error{Path: "json" + nthPath(path, nth), Err: ErrorIndexOutOfRange}
+		}
+		return insertElem(b, start, nth, v), nil
+	})
+}
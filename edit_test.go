@@ -0,0 +1,220 @@
+package lzjson_test
+
+import (
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+func dummyFixture() lzjson.Node {
+	return lzjson.ParseString(`{
+  "name": "foo",
+  "tags": [ "a", "b" ],
+  "owner": {
+    "id": 1,
+    "first name": "John"
+  },
+  "empty": {},
+  "none": []
+}`)
+}
+
+func TestNode_Set(t *testing.T) {
+	type testCase struct {
+		Sel    string
+		Value  interface{}
+		Expect string
+	}
+	tests := []testCase{
+		{"name", "bar", `{
+  "name": "bar",
+  "tags": [ "a", "b" ],
+  "owner": {
+    "id": 1,
+    "first name": "John"
+  },
+  "empty": {},
+  "none": []
+}`},
+		{"tags[1]", map[string]int{"c": 3}, `{
+  "name": "foo",
+  "tags": [ "a", {"c":3} ],
+  "owner": {
+    "id": 1,
+    "first name": "John"
+  },
+  "empty": {},
+  "none": []
+}`},
+		{`owner["first name"]`, nil, `{
+  "name": "foo",
+  "tags": [ "a", "b" ],
+  "owner": {
+    "id": 1,
+    "first name": null
+  },
+  "empty": {},
+  "none": []
+}`},
+		{"owner.last_name", "Doe", `{
+  "name": "foo",
+  "tags": [ "a", "b" ],
+  "owner": {
+    "id": 1,
+    "first name": "John",
+    "last_name": "Doe"
+  },
+  "empty": {},
+  "none": []
+}`},
+		{"empty.hello", lzjson.ParseString("[1, 2]\n"), `{
+  "name": "foo",
+  "tags": [ "a", "b" ],
+  "owner": {
+    "id": 1,
+    "first name": "John"
+  },
+  "empty": {"hello":[1, 2]},
+  "none": []
+}`},
+		{"", true, `true`},
+	}
+
+	for _, test := range tests {
+		orig := dummyFixture()
+		n, err := orig.Set(test.Sel, test.Value)
+		if err != nil {
+			t.Errorf("sel=%#v unexpected error: %s", test.Sel, err.Error())
+			continue
+		}
+		if want, have := test.Expect, string(n.Raw()); want != have {
+			t.Errorf("sel=%#v\nexpected:\n%s\ngot:\n%s", test.Sel, want, have)
+		}
+		if want, have := string(dummyFixture().Raw()), string(orig.Raw()); want != have {
+			t.Errorf("sel=%#v original node modified:\n%s", test.Sel, have)
+		}
+	}
+}
+
+func TestNode_Set_error(t *testing.T) {
+	type testCase struct {
+		Sel    string
+		Expect string
+	}
+	tests := []testCase{
+		{"name.first", "json.name: not an object"},
+		{"owner[0]", "json.owner: not an array"},
+		{"tags[2]", "json.tags[2]: index out of range"},
+		{"nothing.foo", "json.nothing: undefined"},
+		{"tags[", `invalid selector "tags[": unclosed bracket`},
+	}
+	for _, test := range tests {
+		if _, err := dummyFixture().Set(test.Sel, 1); err == nil {
+			t.Errorf("sel=%#v expected error, got nil", test.Sel)
+		} else if want, have := test.Expect, err.Error(); want != have {
+			t.Errorf("sel=%#v expected %#v, got %#v", test.Sel, want, have)
+		}
+	}
+
+	root := lzjson.ParseString(`{"hello": "world"}`)
+	if _, err := root.Get("foo").Set("bar", 1); err == nil {
+		t.Error("expected error, got nil")
+	} else if want, have := "json.foo: undefined", err.Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if _, err := root.Set("foo", make(chan int)); err == nil {
+		t.Error("expected error, got nil")
+	}
+
+	malformed := []struct {
+		JSON   string
+		Expect string
+	}{
+		{`{"a":1} garbage`, "json: invalid character 'g' at offset 8"},
+		{`{"a":1}{"b":2}`, "json: invalid character '{' at offset 7"},
+		{`{"a":1, "c": [1,}`, "json: invalid character '}' at offset 16"},
+		{`{"a":1`, "json: unexpected end of JSON input at offset 6"},
+	}
+	for _, test := range malformed {
+		if _, err := lzjson.ParseString(test.JSON).Set("b", 2); err == nil {
+			t.Errorf("json=%s expected error, got nil", test.JSON)
+		} else if want, have := test.Expect, err.Error(); want != have {
+			t.Errorf("json=%s expected %#v, got %#v", test.JSON, want, have)
+		}
+	}
+}
+
+func TestNode_Delete(t *testing.T) {
+	type testCase struct {
+		Raw    string
+		Sel    string
+		Expect string
+	}
+	tests := []testCase{
+		{`{"a": 1, "b": 2, "c": 3}`, "a", `{"b": 2, "c": 3}`},
+		{`{"a": 1, "b": 2, "c": 3}`, "b", `{"a": 1, "c": 3}`},
+		{`{"a": 1, "b": 2, "c": 3}`, "c", `{"a": 1, "b": 2}`},
+		{`{ "a": 1 }`, "a", `{}`},
+		{`{"a": 1, "b": 2, "a": 3}`, "a", `{"b": 2}`},
+		{"[\n  1,\n  2,\n  3\n]", "[0]", "[\n  2,\n  3\n]"},
+		{"[\n  1,\n  2,\n  3\n]", "[2]", "[\n  1,\n  2\n]"},
+		{`{"x": {"y": [true]}}`, "x.y[0]", `{"x": {"y": []}}`},
+	}
+	for _, test := range tests {
+		n, err := lzjson.ParseString(test.Raw).Delete(test.Sel)
+		if err != nil {
+			t.Errorf("sel=%#v unexpected error: %s", test.Sel, err.Error())
+			continue
+		}
+		if want, have := test.Expect, string(n.Raw()); want != have {
+			t.Errorf("sel=%#v expected %#v, got %#v", test.Sel, want, have)
+		}
+	}
+
+	if _, err := dummyFixture().Delete("owner.age"); err == nil {
+		t.Error("expected error, got nil")
+	} else if want, have := "json.owner.age: undefined", err.Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if _, err := dummyFixture().Delete(""); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestNode_Insert(t *testing.T) {
+	type testCase struct {
+		Raw    string
+		Sel    string
+		Nth    int
+		Expect string
+	}
+	tests := []testCase{
+		{`[1, 2]`, "", 0, `["x", 1, 2]`},
+		{`[1, 2]`, "", 1, `[1, "x", 2]`},
+		{`[1, 2]`, "", 2, `[1, 2, "x"]`},
+		{`[]`, "", 0, `["x"]`},
+		{"[\n  1\n]", "", 1, "[\n  1,\n  \"x\"\n]"},
+		{`{"list": [{"id": 1}]}`, "list", 0, `{"list": ["x",{"id": 1}]}`},
+	}
+	for _, test := range tests {
+		n, err := lzjson.ParseString(test.Raw).Insert(test.Sel, test.Nth, "x")
+		if err != nil {
+			t.Errorf("raw=%#v unexpected error: %s", test.Raw, err.Error())
+			continue
+		}
+		if want, have := test.Expect, string(n.Raw()); want != have {
+			t.Errorf("raw=%#v nth=%d expected %#v, got %#v", test.Raw, test.Nth, want, have)
+		}
+	}
+
+	if _, err := dummyFixture().Insert("tags", 3, "x"); err == nil {
+		t.Error("expected error, got nil")
+	} else if want, have := "json.tags[3]: index out of range", err.Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if _, err := dummyFixture().Insert("owner", 0, "x"); err == nil {
+		t.Error("expected error, got nil")
+	} else if want, have := "json.owner: not an array", err.Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
@@ -0,0 +1,275 @@
+package lzjson
+
+import (
+	"hash"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// equalConfig holds the options of Equal and Hash
+type equalConfig struct {
+	tolerance      float64
+	ignoreKeyOrder bool
+	ignorePaths    map[string]bool
+	nullAsAbsent   bool
+}
+
+// EqualOption configures Equal and Hash
+type EqualOption func(*equalConfig)
+
+// EqualFloatTolerance makes numbers equal if their difference
+// is not larger than the given tolerance. Hash does not take
+// this option into account
+func EqualFloatTolerance(tolerance float64) EqualOption {
+	return func(c *equalConfig) {
+		c.tolerance = tolerance
+	}
+}
+
+// EqualIgnoreKeyOrder makes objects equal if they have the same
+// members, no matter in what order. Otherwise the members
+// must appear in the same order
+func EqualIgnoreKeyOrder() EqualOption {
+	return func(c *equalConfig) {
+		c.ignoreKeyOrder = true
+	}
+}
+
+// EqualIgnorePaths skips the values at the given selectors
+// (e.g. `meta.updatedAt`, `items[0].id`) in the comparison.
+// It panics if a selector is invalid, as the selectors are
+// usually constant
+func EqualIgnorePaths(sels ...string) EqualOption {
+	paths := make([]string, len(sels))
+	for i, sel := range sels {
+		steps, err := parseSel(sel)
+		if err != nil {
+			panic("lzjson: EqualIgnorePaths: " + err.Error())
+		}
+		for _, step := range steps {
+			paths[i] = step.path(paths[i])
+		}
+	}
+	return func(c *equalConfig) {
+		if c.ignorePaths == nil {
+			c.ignorePaths = map[string]bool{}
+		}
+		for _, path := range paths {
+			c.ignorePaths[path] = true
+		}
+	}
+}
+
+// EqualNullAsAbsent treats object members of null value
+// as if they do not exist
+func EqualNullAsAbsent() EqualOption {
+	return func(c *equalConfig) {
+		c.nullAsAbsent = true
+	}
+}
+
+// newEqualConfig returns the config with the options applied
+func newEqualConfig(opts []EqualOption) *equalConfig {
+	c := &equalConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Equal tells if the 2 nodes represent semantically equal JSON
+// values. Insignificant whitespaces, string escapes and number
+// formats (e.g. 1.0 and 1) do not matter.
+//
+// Two undefined nodes are equal, while nodes with parse error
+// are not equal to anything.
+func Equal(a, b Node, opts ...EqualOption) bool {
+	ra, err := rawValue(a)
+	if err != nil {
+		return false
+	}
+	rb, err := rawValue(b)
+	if err != nil {
+		return false
+	}
+	c := newEqualConfig(opts)
+	switch {
+	case c.ignorePaths[""]:
+		return true
+	case len(ra) == 0 || len(rb) == 0:
+		return len(ra) == len(rb)
+	}
+	return c.equal(ra, rb, "")
+}
+
+// jsonEqual tells if the 2 validated raw JSON values are equal,
+// ignoring the order of object keys
+func jsonEqual(a, b []byte) bool {
+	return (&equalConfig{ignoreKeyOrder: true}).equal(a, b, "")
+}
+
+// isNumStart tells if the byte starts a JSON number
+func isNumStart(c byte) bool {
+	return c == '-' || (c >= '0' && c <= '9')
+}
+
+// members returns the effective members of the object in b,
+// with overridden, ignored and (optionally) null members skipped
+func (c *equalConfig) members(b []byte, path string) []objMember {
+	members, _, _ := scanObject(b, 0)
+	effective := make([]objMember, 0, len(members))
+	for i, m := range members {
+		switch {
+		case lastMember(members, m.key) != i:
+		case c.ignorePaths[keyPath(path, m.key)]:
+		case c.nullAsAbsent && string(b[m.valStart:m.valEnd]) == "null":
+		default:
+			effective = append(effective, m)
+		}
+	}
+	if c.ignoreKeyOrder {
+		sort.Slice(effective, func(i, j int) bool {
+			return effective[i].key < effective[j].key
+		})
+	}
+	return effective
+}
+
+// equal compares 2 validated raw JSON values at the path
+func (c *equalConfig) equal(a, b []byte, path string) bool {
+	switch {
+	case a[0] == '{' && b[0] == '{':
+		am, bm := c.members(a, path), c.members(b, path)
+		if len(am) != len(bm) {
+			return false
+		}
+		for i := range am {
+			if am[i].key != bm[i].key {
+				return false
+			}
+			va, vb := a[am[i].valStart:am[i].valEnd], b[bm[i].valStart:bm[i].valEnd]
+			if !c.equal(va, vb, keyPath(path, am[i].key)) {
+				return false
+			}
+		}
+		return true
+	case a[0] == '[' && b[0] == '[':
+		ae, _, _ := scanArray(a, 0)
+		be, _, _ := scanArray(b, 0)
+		if len(ae) != len(be) {
+			return false
+		}
+		for i := range ae {
+			p := nthPath(path, i)
+			if c.ignorePaths[p] {
+				continue
+			}
+			if !c.equal(a[ae[i].start:ae[i].end], b[be[i].start:be[i].end], p) {
+				return false
+			}
+		}
+		return true
+	case a[0] == '"' && b[0] == '"':
+		return unquote(a) == unquote(b)
+	case isNumStart(a[0]) && isNumStart(b[0]):
+		if c.tolerance > 0 {
+			fa, _ := strconv.ParseFloat(string(a), 64)
+			fb, _ := strconv.ParseFloat(string(b), 64)
+			return math.Abs(fa-fb) <= c.tolerance
+		}
+		return normNumber(a) == normNumber(b)
+	}
+	return string(a) == string(b)
+}
+
+// normNumber returns a normalized form of the validated
+// JSON number, so that numerically equal numbers have the
+// same normalized form regardless of precision
+func normNumber(b []byte) string {
+	s := string(b)
+	neg := s[0] == '-'
+	if neg {
+		s = s[1:]
+	}
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(strings.TrimPrefix(s[i+1:], "+"))
+		if err != nil {
+			return string(b) // exponent too large to normalize
+		}
+		s, exp = s[:i], e
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		exp -= len(s) - i - 1
+		s = s[:i] + s[i+1:]
+	}
+	s = strings.TrimLeft(s, "0")
+	for len(s) > 0 && s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+		exp++
+	}
+	if s == "" {
+		return "0"
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s + "e" + strconv.Itoa(exp)
+}
+
+// Hash returns a hash of the node, consistent with Equal
+// of the same options: nodes that are Equal have the same
+// hash. The only exception is EqualFloatTolerance, which is not
+// taken into account by Hash.
+//
+// Nodes with parse error all have the same hash.
+func Hash(n Node, opts ...EqualOption) uint64 {
+	h := fnv.New64a()
+	b, err := rawValue(n)
+	c := newEqualConfig(opts)
+	switch {
+	case err != nil:
+		h.Write([]byte{'!'})
+	case len(b) == 0 || c.ignorePaths[""]:
+	default:
+		c.hash(h, b, "")
+	}
+	return h.Sum64()
+}
+
+// hash writes the validated raw JSON value into h
+func (c *equalConfig) hash(h hash.Hash64, b []byte, path string) {
+	switch {
+	case b[0] == '{':
+		members := c.members(b, path)
+		h.Write([]byte{'{'})
+		for _, m := range members {
+			h.Write([]byte(strconv.Quote(m.key)))
+			c.hash(h, b[m.valStart:m.valEnd], keyPath(path, m.key))
+		}
+		h.Write([]byte{'}'})
+	case b[0] == '[':
+		elems, _, _ := scanArray(b, 0)
+		h.Write([]byte{'['})
+		for i, e := range elems {
+			p := nthPath(path, i)
+			if c.ignorePaths[p] {
+				h.Write([]byte{'_'})
+				continue
+			}
+			c.hash(h, b[e.start:e.end], p)
+		}
+		h.Write([]byte{']'})
+	case b[0] == '"':
+		h.Write([]byte(strconv.Quote(unquote(b))))
+	case isNumStart(b[0]):
+		h.Write([]byte(normNumber(b)))
+	default:
+		h.Write(b)
+	}
+	// mark the end of value to avoid ambiguity
+	h.Write([]byte{','})
+}
@@ -0,0 +1,111 @@
+package lzjson_test
+
+import (
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		A     string
+		B     string
+		Opts  []lzjson.EqualOption
+		Equal bool
+	}{
+		{`{"a": 1, "b": [true, null]}`, "{\n  \"a\":1,\n  \"b\":[ true,null ]\n}", nil, true},
+		{`1.0`, `1`, nil, true},
+		{`-0`, `0.0`, nil, true},
+		{`1.5e3`, `1500`, nil, true},
+		{`150E-2`, `1.5`, nil, true},
+		{`9007199254740993`, `9007199254740992`, nil, false},
+		{`"café \/"`, `"café /"`, nil, true},
+		{`"1"`, `1`, nil, false},
+		{`[1, 2]`, `[2, 1]`, nil, false},
+		{`[1, 2]`, `[1, 2, 3]`, nil, false},
+		{`{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`, nil, false},
+		{`{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`, []lzjson.EqualOption{lzjson.EqualIgnoreKeyOrder()}, true},
+		{`{"a": 1, "a": 2}`, `{"a": 2}`, nil, true},
+		{`0.1`, `0.10000001`, nil, false},
+		{`0.1`, `0.10000001`, []lzjson.EqualOption{lzjson.EqualFloatTolerance(1e-6)}, true},
+		{`[0.1]`, `[0.2]`, []lzjson.EqualOption{lzjson.EqualFloatTolerance(1e-6)}, false},
+		{`{"a": 1, "b": null}`, `{"a": 1}`, nil, false},
+		{`{"a": 1, "b": null}`, `{"a": 1}`, []lzjson.EqualOption{lzjson.EqualNullAsAbsent()}, true},
+		{
+			`{"id": 1, "meta": {"updated": "yesterday", "by": "me"}, "items": [{"id": 10}]}`,
+			`{"id": 1, "meta": {"updated": "today", "by": "me"}, "items": [{"id": 20}]}`,
+			[]lzjson.EqualOption{lzjson.EqualIgnorePaths("meta.updated", "items[0].id")},
+			true,
+		},
+		{
+			`{"id": 1, "meta": {"updated": "yesterday"}}`,
+			`{"id": 2, "meta": {"updated": "today"}}`,
+			[]lzjson.EqualOption{lzjson.EqualIgnorePaths("meta")},
+			false,
+		},
+	}
+	for _, test := range tests {
+		a, b := lzjson.ParseString(test.A), lzjson.ParseString(test.B)
+		if want, have := test.Equal, lzjson.Equal(a, b, test.Opts...); want != have {
+			t.Errorf("a=%s b=%s expected %#v, got %#v", test.A, test.B, want, have)
+		}
+		if want, have := test.Equal, lzjson.Equal(b, a, test.Opts...); want != have {
+			t.Errorf("a=%s b=%s (swapped) expected %#v, got %#v", test.A, test.B, want, have)
+		}
+	}
+}
+
+func TestEqual_undefined(t *testing.T) {
+	root := lzjson.ParseString(`{"a": 1}`)
+	if want, have := true, lzjson.Equal(lzjson.NewNode(), lzjson.ParseString("")); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := false, lzjson.Equal(root.Get("b"), root.Get("b")); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := false, lzjson.Equal(root, lzjson.NewNode()); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
+
+func TestEqualIgnorePaths_invalid(t *testing.T) {
+	defer func() {
+		if want, have := `lzjson: EqualIgnorePaths: invalid selector "meta.[": expected property name after dot`, recover(); want != have {
+			t.Errorf("expected %#v, got %#v", want, have)
+		}
+	}()
+	lzjson.EqualIgnorePaths("id", "meta.[")
+}
+
+func TestHash(t *testing.T) {
+	tests := []struct {
+		A    string
+		B    string
+		Opts []lzjson.EqualOption
+	}{
+		{`{"a": 1, "b": [true, null]}`, "{\n  \"a\":1,\n  \"b\":[ true,null ]\n}", nil},
+		{`[1.0, "é"]`, `[1, "é"]`, nil},
+		{`{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`, []lzjson.EqualOption{lzjson.EqualIgnoreKeyOrder()}},
+		{`{"a": 1, "b": null}`, `{"a": 1}`, []lzjson.EqualOption{lzjson.EqualNullAsAbsent()}},
+		{`{"a": 1, "t": 10}`, `{"a": 1, "t": 20}`, []lzjson.EqualOption{lzjson.EqualIgnorePaths("t")}},
+	}
+	for _, test := range tests {
+		a, b := lzjson.ParseString(test.A), lzjson.ParseString(test.B)
+		if !lzjson.Equal(a, b, test.Opts...) {
+			t.Errorf("a=%s b=%s expected to be equal", test.A, test.B)
+		}
+		if want, have := lzjson.Hash(a, test.Opts...), lzjson.Hash(b, test.Opts...); want != have {
+			t.Errorf("a=%s b=%s expected same hash, got %x and %x", test.A, test.B, want, have)
+		}
+	}
+
+	different := []string{`1`, `"1"`, `[1]`, `{"1": 1}`, `[1, 2]`, `[12]`, `{"a": 1, "b": 2}`, `{"b": 2, "a": 1}`, `null`}
+	seen := map[uint64]string{}
+	for _, str := range different {
+		h := lzjson.Hash(lzjson.ParseString(str))
+		if prev, ok := seen[h]; ok {
+			t.Errorf("expected different hash for %s and %s", prev, str)
+		}
+		seen[h] = str
+	}
+}
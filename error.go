@@ -2,6 +2,12 @@
 
 package lzjson
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
 // ParseError describe error natures in parsing process
 type ParseError int
 
@@ -10,6 +16,11 @@ const (
 	ErrorUndefined ParseError = iota
 	ErrorNotObject
 	ErrorNotArray
+	ErrorLimitExceeded
+	ErrorTypeMismatch
+	ErrorSyntax
+	ErrorIndexOutOfRange
+	ErrorNonFinite
 )
 
 func (err ParseError) Error() string {
@@ -20,10 +31,33 @@ func (err ParseError) Error() string {
 		return "not an object"
 	case ErrorNotArray:
 		return "not an array"
+	case ErrorLimitExceeded:
+		return "limit exceeded"
+	case ErrorTypeMismatch:
+		return "type mismatch"
+	case ErrorSyntax:
+		return "syntax error"
+	case ErrorIndexOutOfRange:
+		return "index out of range"
+	case ErrorNonFinite:
+		return "non-finite number"
 	}
 	return "unknown parse error"
 }
 
+// Is tells if the error is of the target kind, for errors.Is.
+// ErrorNotObject and ErrorNotArray are ErrorTypeMismatch, and
+// ErrorIndexOutOfRange is ErrorUndefined
+func (err ParseError) Is(target error) bool {
+	switch err {
+	case ErrorNotObject, ErrorNotArray:
+		return target == ErrorTypeMismatch
+	case ErrorIndexOutOfRange:
+		return target == ErrorUndefined
+	}
+	return false
+}
+
 // GoString implements fmt.GoStringer
 func (err ParseError) GoString() string {
 	return "lzjson." + err.String()
@@ -31,19 +65,113 @@ func (err ParseError) GoString() string {
 
 // Error is the generic error for parsing
 type Error struct {
+	// Path is where the error actually happened
 	Path string
-	Err  error
+
+	// Requested is the path of the value requested by
+	// Get or GetN. It differs from Path if the resolution
+	// failed at one of its parents. Empty if unknown
+	Requested string
+
+	Err error
+}
+
+// cause describes the failure at Path, for the
+// message of a failed request of another path
+func (err Error) cause() string {
+	switch err.Err {
+	case ErrorUndefined:
+		return err.Path + " not found"
+	case ErrorIndexOutOfRange:
+		return err.Path + " out of range"
+	case ErrorNotObject:
+		return err.Path + " is not an object"
+	case ErrorNotArray:
+		return err.Path + " is not an array"
+	case ErrorLimitExceeded:
+		return err.Path + " exceeds the limits"
+	}
+	return err.Path + ": " + err.Err.Error()
+}
+
+// kind names the kind of the cause, for the
+// message of a failed request of another path
+func (err Error) kind() string {
+	for _, kind := range []ParseError{
+		ErrorUndefined,
+		ErrorTypeMismatch,
+		ErrorLimitExceeded,
+		ErrorSyntax,
+		ErrorNonFinite,
+	} {
+		if errors.Is(err.Err, kind) {
+			return kind.Error()
+		}
+	}
+	return "invalid"
 }
 
 // Error implements error type
+//
+// If the error happened at a parent of the requested
+// path, the message tells both paths with the kind of
+// the cause. e.g. `json.a.b[3]: undefined (json.a not found)`
+// or `json.a.b: type mismatch (json.a is not an object)`
 func (err Error) Error() string {
+	if err.Requested != "" && err.Requested != err.Path {
+		return err.Requested + ": " + err.kind() + " (" + err.cause() + ")"
+	}
 	if err.Path != "" {
 		return err.Path + ": " + err.Err.Error()
 	}
 	return err.Err.Error()
 }
 
+// Unwrap returns the underlying error, for errors.Is and errors.As
+func (err Error) Unwrap() error {
+	return err.Err
+}
+
 // String implements Stringer
 func (err Error) String() string {
 	return err.Error()
 }
+
+// mismatchError describes a value not of the expected
+// type. It matches ErrorTypeMismatch with errors.Is
+type mismatchError struct {
+	expected string
+	got      string
+}
+
+// Error implements error type
+func (err mismatchError) Error() string {
+	return fmt.Sprintf("expected %s, got %s", err.expected, err.got)
+}
+
+// Is tells if the target is ErrorTypeMismatch, for errors.Is
+func (err mismatchError) Is(target error) bool {
+	return target == ErrorTypeMismatch
+}
+
+// Errors is a list of Error, for operations that
+// report more than one error at a time
+type Errors []Error
+
+// Error implements error type
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors, for errors.Is and errors.As
+func (errs Errors) Unwrap() []error {
+	unwrapped := make([]error, len(errs))
+	for i, err := range errs {
+		unwrapped[i] = err
+	}
+	return unwrapped
+}
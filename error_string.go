@@ -4,9 +4,9 @@ package lzjson
 
 import "fmt"
 
-const _ParseError_name = "ErrorUndefinedErrorNotObjectErrorNotArray"
+const _ParseError_name = "ErrorUndefinedErrorNotObjectErrorNotArrayErrorLimitExceededErrorTypeMismatchErrorSyntaxErrorIndexOutOfRangeErrorNonFinite"
 
-var _ParseError_index = [...]uint8{0, 14, 28, 41}
+var _ParseError_index = [...]uint8{0, 14, 28, 41, 59, 76, 87, 107, 121}
 
 func (i ParseError) String() string {
 	if i < 0 || i >= ParseError(len(_ParseError_index)-1) {
@@ -1,6 +1,7 @@
 package lzjson_test
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -17,6 +18,15 @@ func TestParseError_GoString(t *testing.T) {
 	if want, have := "lzjson.ErrorNotArray", fmt.Sprintf("%#v", lzjson.ErrorNotArray); want != have {
 		t.Errorf("expected %#v, got %#v", want, have)
 	}
+	if want, have := "lzjson.ErrorLimitExceeded", fmt.Sprintf("%#v", lzjson.ErrorLimitExceeded); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := "lzjson.ErrorIndexOutOfRange", fmt.Sprintf("%#v", lzjson.ErrorIndexOutOfRange); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := "lzjson.ErrorNonFinite", fmt.Sprintf("%#v", lzjson.ErrorNonFinite); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
 	if want, have := "unknown parse error", lzjson.ParseError(-1).Error(); want != have {
 		t.Errorf("expected %#v, got %#v", want, have)
 	}
@@ -34,3 +44,112 @@ func TestError_String(t *testing.T) {
 		t.Errorf("\nexpected:\n%s\ngot:\n%s", want, have)
 	}
 }
+
+func TestError_Requested(t *testing.T) {
+	tests := []struct {
+		err  lzjson.Error
+		want string
+	}{
+		{lzjson.Error{Path: "json.a", Requested: "json.a", Err: lzjson.ErrorUndefined}, "json.a: undefined"},
+		{lzjson.Error{Path: "json.a", Requested: "json.a.b[3]", Err: lzjson.ErrorUndefined}, "json.a.b[3]: undefined (json.a not found)"},
+		{lzjson.Error{Path: "json.a", Requested: "json.a[3].b", Err: lzjson.ErrorNotArray}, "json.a[3].b: type mismatch (json.a is not an array)"},
+		{lzjson.Error{Path: "json.a", Requested: "json.a.b", Err: lzjson.ErrorNotObject}, "json.a.b: type mismatch (json.a is not an object)"},
+		{lzjson.Error{Path: "json.a[3]", Requested: "json.a[3].b", Err: lzjson.ErrorIndexOutOfRange}, "json.a[3].b: undefined (json.a[3] out of range)"},
+		{lzjson.Error{Path: "json.a", Requested: "json.a.b", Err: lzjson.ErrorLimitExceeded}, "json.a.b: limit exceeded (json.a exceeds the limits)"},
+		{lzjson.Error{Path: "json.a", Requested: "json.a.b", Err: lzjson.ErrorSyntax}, "json.a.b: syntax error (json.a: syntax error)"},
+		{lzjson.Error{Path: "json", Requested: "json.a", Err: errors.New("unexpected EOF")}, "json.a: invalid (json: unexpected EOF)"},
+	}
+	for i, test := range tests {
+		if want, have := test.want, test.err.Error(); want != have {
+			t.Errorf("test %d: expected %#v, got %#v", i, want, have)
+		}
+	}
+}
+
+func TestErrors_Error(t *testing.T) {
+	errs := lzjson.Errors{
+		{Path: "json.foo", Err: lzjson.ErrorUndefined},
+		{Path: "json.bar", Err: lzjson.ErrorNotArray},
+	}
+	if want, have := "json.foo: undefined\njson.bar: not an array", errs.Error(); want != have {
+		t.Errorf("\nexpected:\n%s\ngot:\n%s", want, have)
+	}
+}
+
+func TestError_Is(t *testing.T) {
+	root := lzjson.ParseString(`{"hello": [{"name": "world"}], "number": 42}`)
+
+	tests := []struct {
+		Node  lzjson.Node
+		Is    []error
+		IsNot []error
+	}{
+		{
+			root.Get("foo").GetN(0).Get("bar"),
+			[]error{lzjson.ErrorUndefined},
+			[]error{lzjson.ErrorIndexOutOfRange, lzjson.ErrorTypeMismatch},
+		},
+		{
+			root.Get("hello").GetN(3).Get("name"),
+			[]error{lzjson.ErrorIndexOutOfRange, lzjson.ErrorUndefined},
+			[]error{lzjson.ErrorTypeMismatch},
+		},
+		{
+			root.Get("hello").Get("name"),
+			[]error{lzjson.ErrorNotObject, lzjson.ErrorTypeMismatch},
+			[]error{lzjson.ErrorNotArray, lzjson.ErrorUndefined},
+		},
+		{
+			root.Get("number").GetN(0),
+			[]error{lzjson.ErrorNotArray, lzjson.ErrorTypeMismatch},
+			[]error{lzjson.ErrorNotObject, lzjson.ErrorSyntax},
+		},
+		{
+			lzjson.ParseString(`{"a": }`).Get("a"),
+			[]error{lzjson.ErrorSyntax},
+			[]error{lzjson.ErrorUndefined, lzjson.ErrorTypeMismatch},
+		},
+		{
+			lzjson.ParseString(`[1, }`).GetN(0),
+			[]error{lzjson.ErrorSyntax},
+			[]error{lzjson.ErrorUndefined},
+		},
+	}
+	for i, test := range tests {
+		err := test.Node.ParseError()
+		if err == nil {
+			t.Errorf("test %d: expected error, got nil", i)
+			continue
+		}
+		for _, target := range test.Is {
+			if !errors.Is(err, target) {
+				t.Errorf("test %d: expected %s to be %#v", i, err.Error(), target)
+			}
+		}
+		for _, target := range test.IsNot {
+			if errors.Is(err, target) {
+				t.Errorf("test %d: expected %s not to be %#v", i, err.Error(), target)
+			}
+		}
+		var lerr lzjson.Error
+		if !errors.As(err, &lerr) {
+			t.Errorf("test %d: expected errors.As to find lzjson.Error", i)
+		}
+	}
+}
+
+func TestErrors_Is(t *testing.T) {
+	c := lzjson.NewChecker(lzjson.ParseString(`{"name": 42}`))
+	c.String("name")
+	err := c.Err()
+	if !errors.Is(err, lzjson.ErrorTypeMismatch) {
+		t.Errorf("expected %s to be lzjson.ErrorTypeMismatch", err.Error())
+	}
+	if errors.Is(err, lzjson.ErrorUndefined) {
+		t.Errorf("expected %s not to be lzjson.ErrorUndefined", err.Error())
+	}
+	c.String("email")
+	if err := c.Err(); !errors.Is(err, lzjson.ErrorUndefined) {
+		t.Errorf("expected %s to be lzjson.ErrorUndefined", err.Error())
+	}
+}
@@ -49,16 +49,16 @@ func Example() {
 
 	// parse errors inherit along the path, no matter how deep you went
 	if err := data.Get("foo").GetN(0).ParseError(); err != nil {
-		fmt.Println(err.Error()) // output "json.foo: undefined"
+		fmt.Println(err.Error()) // output "json.foo[0]: undefined (json.foo not found)"
 	}
 	if err := data.Get("hello").Get("notexists").Get("name").ParseError(); err != nil {
-		fmt.Println(err.Error()) // output "json.hello: not an object"
+		fmt.Println(err.Error()) // output "json.hello.notexists.name: type mismatch (json.hello is not an object)"
 	}
 	if err := data.Get("hello").GetN(0).Get("notexists").Get("name").ParseError(); err != nil {
-		fmt.Println(err.Error()) // output "json.hello[0].notexists: undefined"
+		fmt.Println(err.Error()) // output "json.hello[0].notexists.name: undefined (json.hello[0].notexists not found)"
 	}
 	if err := data.Get("hello").GetN(10).GetN(0).Get("name").ParseError(); err != nil {
-		fmt.Println(err.Error()) // output "json.hello[10]: undefined"
+		fmt.Println(err.Error()) // output "json.hello[10][0].name: undefined (json.hello[10] out of range)"
 	}
 
 	// Output:
@@ -66,8 +66,8 @@ func Example() {
 	// 123
 	// world 3
 	// numbers of item in json.hello: 3
-	// json.foo: undefined
-	// json.hello: not an object
-	// json.hello[0].notexists: undefined
-	// json.hello[10]: undefined
+	// json.foo[0]: undefined (json.foo not found)
+	// json.hello.notexists.name: type mismatch (json.hello is not an object)
+	// json.hello[0].notexists.name: undefined (json.hello[0].notexists not found)
+	// json.hello[10][0].name: undefined (json.hello[10] out of range)
 }
@@ -0,0 +1,231 @@
+package lzjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// reformat returns a new Node of the raw JSON value rewritten by fn.
+// Undefined node and node with parse error are returned as-is
+func (n *rootNode) reformat(fn func(dst *bytes.Buffer, src []byte) error) Node {
+	b, err := rawValue(n)
+	if err == nil && len(b) == 0 {
+		return n
+	}
+	var buf bytes.Buffer
+	if err == nil {
+		err = fn(&buf, b)
+	}
+	if err != nil {
+		if n.err != nil {
+			return n
+		}
+		if _, ok := err.(Error); ok {
+			return &rootNode{path: n.path, err: err}
+		}
+		return &rootNode{
+			path: n.path,
+			err: Error{
+				Path: "json" + n.path,
+				Err:  err,
+			},
+		}
+	}
+	return &rootNode{
+		path: n.path,
+		buf:  buf.Bytes(),
+		obj:  &objectCache{},
+	}
+}
+
+// Indent implements Node
+func (n *rootNode) Indent(prefix, indent string) Node {
+	return n.reformat(func(dst *bytes.Buffer, src []byte) error {
+		return json.Indent(dst, src, prefix, indent)
+	})
+}
+
+// Compact implements Node
+func (n *rootNode) Compact() Node {
+	return n.reformat(func(dst *bytes.Buffer, src []byte) error {
+		return json.Compact(dst, src)
+	})
+}
+
+// Canonical implements Node
+func (n *rootNode) Canonical() Node {
+	return n.reformat(canonicalize)
+}
+
+// checkUnicode returns an error if the validated raw JSON
+// string is not well-formed Unicode, i.e. has invalid UTF-8
+// or an escaped surrogate not in a pair (e.g. "\ud800")
+func checkUnicode(raw []byte) error {
+	if !utf8.Valid(raw) {
+		return fmt.Errorf("string %s is not valid UTF-8", raw)
+	}
+	lone := fmt.Errorf("string %s has a lone surrogate", raw)
+	high := false // if the previous character is an escaped high surrogate
+	for i := 1; i < len(raw)-1; i++ {
+		var r uint64 = 0 // not a surrogate
+		if raw[i] == '\\' && raw[i+1] == 'u' {
+			r, _ = strconv.ParseUint(string(raw[i+2:i+6]), 16, 32)
+			i += 5
+		} else if raw[i] == '\\' {
+			i++
+		}
+		switch {
+		case high && r >= 0xdc00 && r <= 0xdfff:
+			high = false
+		case high || (r >= 0xdc00 && r <= 0xdfff):
+			return lone
+		default:
+			high = r >= 0xd800 && r <= 0xdbff
+		}
+	}
+	if high {
+		return lone
+	}
+	return nil
+}
+
+// utf16Less tells if a comes before b when compared
+// as arrays of UTF-16 code units
+func utf16Less(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+// canonicalize writes the validated raw JSON value into
+// dst in the form of JSON Canonicalization Scheme (RFC 8785)
+func canonicalize(dst *bytes.Buffer, b []byte) error {
+	switch {
+	case b[0] == '{':
+		members, _, _ := scanObject(b, 0)
+		effective := make([]objMember, 0, len(members))
+		for i, m := range members {
+			if lastMember(members, m.key) == i {
+				effective = append(effective, m)
+			}
+		}
+		sort.Slice(effective, func(i, j int) bool {
+			return utf16Less(effective[i].key, effective[j].key)
+		})
+		dst.WriteByte('{')
+		for i, m := range effective {
+			if i > 0 {
+				dst.WriteByte(',')
+			}
+			if err := checkUnicode(b[m.keyStart:m.keyEnd]); err != nil {
+				return err
+			}
+			writeCanonicalString(dst, m.key)
+			dst.WriteByte(':')
+			if err := canonicalize(dst, b[m.valStart:m.valEnd]); err != nil {
+				return err
+			}
+		}
+		dst.WriteByte('}')
+	case b[0] == '[':
+		elems, _, _ := scanArray(b, 0)
+		dst.WriteByte('[')
+		for i, e := range elems {
+			if i > 0 {
+				dst.WriteByte(',')
+			}
+			if err := canonicalize(dst, b[e.start:e.end]); err != nil {
+				return err
+			}
+		}
+		dst.WriteByte(']')
+	case b[0] == '"':
+		if err := checkUnicode(b); err != nil {
+			return err
+		}
+		writeCanonicalString(dst, unquote(b))
+	case isNumStart(b[0]):
+		f, err := strconv.ParseFloat(string(b), 64)
+		if err != nil {
+			return fmt.Errorf("number %s cannot be represented in IEEE 754 double precision", b)
+		}
+		dst.WriteString(formatES6(f))
+	default:
+		dst.Write(b)
+	}
+	return nil
+}
+
+// writeCanonicalString writes the string as JSON string
+// with the minimal escaping of ECMAScript JSON.stringify
+func writeCanonicalString(dst *bytes.Buffer, str string) {
+	dst.WriteByte('"')
+	for _, r := range str {
+		switch r {
+		case '"':
+			dst.WriteString(`\"`)
+		case '\\':
+			dst.WriteString(`\\`)
+		case '\b':
+			dst.WriteString(`\b`)
+		case '\f':
+			dst.WriteString(`\f`)
+		case '\n':
+			dst.WriteString(`\n`)
+		case '\r':
+			dst.WriteString(`\r`)
+		case '\t':
+			dst.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(dst, `\u%04x`, r)
+			} else {
+				dst.WriteRune(r)
+			}
+		}
+	}
+	dst.WriteByte('"')
+}
+
+// formatES6 formats the finite number as ECMAScript
+// Number.prototype.toString does
+func formatES6(f float64) string {
+	if f == 0 {
+		return "0" // including negative zero
+	}
+	sign := ""
+	if f < 0 {
+		sign, f = "-", -f
+	}
+
+	// shortest round-trip digits and the decimal exponent
+	e := strconv.FormatFloat(f, 'e', -1, 64)
+	i := strings.IndexByte(e, 'e')
+	digits := strings.Replace(e[:i], ".", "", 1)
+	exp, _ := strconv.Atoi(e[i+1:])
+	k, n := len(digits), exp+1 // value is 0.digits * 10^n
+
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits
+	}
+	mantissa := digits[:1]
+	if k > 1 {
+		mantissa += "." + digits[1:]
+	}
+	return fmt.Sprintf("%s%se%+d", sign, mantissa, n-1)
+}
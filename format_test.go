@@ -0,0 +1,146 @@
+package lzjson_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+func TestNode_Indent(t *testing.T) {
+	n := lzjson.ParseString(`{"b": [1.50, "\u00e9"],"a" :{}}`)
+	want := "{\n>\t\"b\": [\n>\t\t1.50,\n>\t\t\"\\u00e9\"\n>\t],\n>\t\"a\": {}\n>}"
+	if have := string(n.Indent(">", "\t").Raw()); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+
+	// nested value in indented document
+	n = lzjson.ParseString("{\n  \"a\": {\n    \"b\": 1\n  }\n}")
+	if want, have := "{\n  \"b\": 1\n}", string(n.Get("a").Indent("", "  ").Raw()); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
+
+func TestNode_Compact(t *testing.T) {
+	n := lzjson.ParseString("\n{ \"b\" : [ 1.50 , \"a b\" ],\n\t\"a\": {} }\n")
+	if want, have := `{"b":[1.50,"a b"],"a":{}}`, string(n.Compact().Raw()); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
+
+func TestNode_Compact_error(t *testing.T) {
+	undefined := lzjson.ParseString(`{}`).Get("a")
+	if want, have := undefined, undefined.Compact(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+
+	n := lzjson.ParseString(`{"a": [1, }`)
+	for _, reformatted := range []lzjson.Node{n.Compact(), n.Indent("", " "), n.Canonical()} {
+		if want, have := lzjson.TypeError, reformatted.Type(); want != have {
+			t.Errorf("expected %s, got %s", want, have)
+		}
+		if err := reformatted.ParseError(); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	}
+}
+
+func TestNode_Canonical(t *testing.T) {
+	// examples of RFC 8785 section 3.2.2 and 3.2.3
+	tests := []struct {
+		Input string
+		Want  string
+	}{
+		{
+			`{
+				"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+				"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+				"literals": [null, true, false]
+			}`,
+			`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			`{
+				"\u20ac": "Euro Sign",
+				"\r": "Carriage Return",
+				"\ufb33": "Hebrew Letter Dalet With Dagesh",
+				"1": "One",
+				"\ud83d\ude00": "Emoji: Grinning Face",
+				"\u0080": "Control",
+				"\u00f6": "Latin Small Letter O With Diaeresis"
+			}`,
+			"{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\"," +
+				"\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\"," +
+				"\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{`{"a": 1, "a": 2}`, `{"a":2}`},
+		{` [ "\t\u001f\u007f" ] `, "[\"\\t\\u001f\u007f\"]"},
+	}
+	for _, test := range tests {
+		if want, have := test.Want, string(lzjson.ParseString(test.Input).Canonical().Raw()); want != have {
+			t.Errorf("input=%s expected %s, got %s", test.Input, want, have)
+		}
+	}
+
+	if err := lzjson.ParseString(`[1e400]`).Canonical().ParseError(); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+
+	// input must be well-formed Unicode
+	for _, input := range []string{
+		`"\ud800"`,
+		`"\udc00 low"`,
+		`["\ud83d\u0041"]`,
+		`{"\ud83d": 1}`,
+		`"\ud83d\\ude00"`,
+		"\"\xff\"",
+	} {
+		if err := lzjson.ParseString(input).Canonical().ParseError(); err == nil {
+			t.Errorf("input=%s expected error, got nil", input)
+		}
+	}
+	// an escaped backslash followed by "ud800" is not a surrogate
+	if want, have := `"\\ud800"`, string(lzjson.ParseString(`"\\ud800"`).Canonical().Raw()); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+}
+
+func TestNode_Canonical_numbers(t *testing.T) {
+	// number examples of RFC 8785 appendix B
+	tests := []struct {
+		Bits uint64
+		Want string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+	for _, test := range tests {
+		input := strconv.FormatFloat(math.Float64frombits(test.Bits), 'g', -1, 64)
+		if want, have := test.Want, string(lzjson.ParseString(input).Canonical().Raw()); want != have {
+			t.Errorf("bits=%016x input=%s expected %s, got %s", test.Bits, input, want, have)
+		}
+	}
+}
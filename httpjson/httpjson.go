@@ -0,0 +1,166 @@
+// Package httpjson decodes and writes JSON bodies of
+// net/http requests and responses as lzjson.Node
+package httpjson
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-restit/lzjson"
+)
+
+// BodyError describes errors of HTTP message body
+// other than the parse errors of lzjson
+type BodyError int
+
+// types of error
+const (
+	ErrorContentType BodyError = iota
+	ErrorContentEncoding
+	ErrorEmptyBody
+)
+
+// Error implements error type
+func (err BodyError) Error() string {
+	switch err {
+	case ErrorContentType:
+		return "unsupported Content-Type"
+	case ErrorContentEncoding:
+		return "unsupported Content-Encoding"
+	case ErrorEmptyBody:
+		return "empty body"
+	}
+	return "unknown body error"
+}
+
+// ReadError is an error reading or decompressing the body
+type ReadError struct {
+	Err error
+}
+
+// Error implements error type
+func (err ReadError) Error() string {
+	return err.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (err ReadError) Unwrap() error {
+	return err.Err
+}
+
+// bodyReader wraps the errors of r, other than io.EOF,
+// in ReadError
+type bodyReader struct {
+	r io.Reader
+}
+
+// Read implements io.Reader
+func (r bodyReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = ReadError{err}
+	}
+	return n, err
+}
+
+// isJSON tells if the Content-Type header is
+// application/json or application/*+json, in UTF-8
+func isJSON(contentType string) bool {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if charset, ok := params["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+		return false
+	}
+	return mediaType == "application/json" ||
+		(strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
+// decodeBody decodes the body in the given Content-Encoding
+// and closes it afterwards
+func decodeBody(header http.Header, body io.ReadCloser, opts lzjson.Options) lzjson.Node {
+	if body == nil || body == http.NoBody {
+		return errNode(ErrorEmptyBody)
+	}
+	defer body.Close()
+
+	if !isJSON(header.Get("Content-Type")) {
+		return errNode(ErrorContentType)
+	}
+
+	// encodings are listed in the order applied
+	var r io.Reader = body
+	encodings := strings.Split(header.Get("Content-Encoding"), ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var err error
+		switch strings.ToLower(strings.TrimSpace(encodings[i])) {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			r, err = gzip.NewReader(r)
+		case "deflate":
+			r, err = zlib.NewReader(r)
+		default:
+			return errNode(ErrorContentEncoding)
+		}
+		if err != nil {
+			return errNode(ReadError{err})
+		}
+	}
+	return lzjson.DecodeWithOptions(bodyReader{r}, opts)
+}
+
+// errNode returns a Node with the given parse error
+func errNode(err error) lzjson.Node {
+	return lzjson.Decode(errReader{err})
+}
+
+// errReader is an io.Reader always failing with err
+type errReader struct {
+	err error
+}
+
+// Read implements io.Reader
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// DecodeRequest reads and decodes the JSON body of the request
+// with the limits of opts, then closes the body.
+//
+// The Content-Type must be application/json or application/*+json
+// in UTF-8, otherwise the returned Node has ErrorContentType as
+// parse error. The body may be compressed with gzip or deflate
+// as specified by Content-Encoding. Other encodings result in
+// ErrorContentEncoding. Errors reading or decompressing the body
+// are returned as ReadError. MaxBytes limits the decompressed size.
+// A request without body results in ErrorEmptyBody.
+func DecodeRequest(r *http.Request, opts lzjson.Options) lzjson.Node {
+	return decodeBody(r.Header, r.Body, opts)
+}
+
+// DecodeResponse reads and decodes the JSON body of the
+// response, then closes the body. The Content-Type and
+// Content-Encoding are handled as in DecodeRequest
+func DecodeResponse(resp *http.Response) lzjson.Node {
+	return decodeBody(resp.Header, resp.Body, lzjson.Options{})
+}
+
+// WriteNode writes the node as a JSON response with the
+// status code. An undefined node is written as null. If
+// the node cannot be marshaled, nothing is written and the
+// error is returned
+func WriteNode(w http.ResponseWriter, status int, n lzjson.Node) error {
+	b, err := n.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,197 @@
+package httpjson_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-restit/lzjson"
+	"github.com/go-restit/lzjson/httpjson"
+)
+
+// closeRecorder records if the body is closed
+type closeRecorder struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func gzipped(str string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(str))
+	w.Close()
+	return buf.Bytes()
+}
+
+func deflated(str string) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write([]byte(str))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestDecodeRequest(t *testing.T) {
+	tests := []struct {
+		ContentType     string
+		ContentEncoding string
+		Body            []byte
+	}{
+		{"application/json", "", []byte(`{"hello": "world"}`)},
+		{"application/json; charset=UTF-8", "", []byte(`{"hello": "world"}`)},
+		{"application/merge-patch+json", "identity", []byte(`{"hello": "world"}`)},
+		{"application/json", "gzip", gzipped(`{"hello": "world"}`)},
+		{"application/json", "deflate", deflated(`{"hello": "world"}`)},
+		{"application/json", "deflate, gzip", gzipped(string(deflated(`{"hello": "world"}`)))},
+	}
+	for _, test := range tests {
+		body := &closeRecorder{Reader: bytes.NewReader(test.Body)}
+		r := httptest.NewRequest("POST", "/", body)
+		r.Header.Set("Content-Type", test.ContentType)
+		r.Header.Set("Content-Encoding", test.ContentEncoding)
+
+		n := httpjson.DecodeRequest(r, lzjson.Options{})
+		if err := n.ParseError(); err != nil {
+			t.Errorf("type=%s encoding=%s unexpected error: %s", test.ContentType, test.ContentEncoding, err.Error())
+			continue
+		}
+		if want, have := "world", n.Get("hello").String(); want != have {
+			t.Errorf("expected %#v, got %#v", want, have)
+		}
+		if !body.closed {
+			t.Errorf("type=%s encoding=%s expected body to be closed", test.ContentType, test.ContentEncoding)
+		}
+	}
+}
+
+func TestDecodeRequest_error(t *testing.T) {
+	tests := []struct {
+		ContentType     string
+		ContentEncoding string
+		Body            []byte
+		Err             string
+	}{
+		{"", "", []byte(`{}`), "unsupported Content-Type"},
+		{"text/plain", "", []byte(`{}`), "unsupported Content-Type"},
+		{"application/json; charset=latin1", "", []byte(`{}`), "unsupported Content-Type"},
+		{"application/json", "br", []byte(`{}`), "unsupported Content-Encoding"},
+		{"application/json", "gzip", []byte(`{}`), "unexpected EOF"},
+		{"application/json", "", []byte(`{"hello": "world!"}`), "json: limit exceeded"},
+		{"application/json", "gzip", gzipped(`{"hello": "world!"}`), "json: limit exceeded"},
+	}
+	for _, test := range tests {
+		body := &closeRecorder{Reader: bytes.NewReader(test.Body)}
+		r := httptest.NewRequest("POST", "/", body)
+		r.Header.Set("Content-Type", test.ContentType)
+		r.Header.Set("Content-Encoding", test.ContentEncoding)
+
+		err := httpjson.DecodeRequest(r, lzjson.Options{MaxBytes: 18}).ParseError()
+		if err == nil {
+			t.Errorf("type=%s encoding=%s expected error, got nil", test.ContentType, test.ContentEncoding)
+		} else if want, have := test.Err, err.Error(); want != have {
+			t.Errorf("type=%s encoding=%s expected %#v, got %#v", test.ContentType, test.ContentEncoding, want, have)
+		}
+		if !body.closed {
+			t.Errorf("type=%s encoding=%s expected body to be closed", test.ContentType, test.ContentEncoding)
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+	if want, have := error(httpjson.ErrorContentType), httpjson.DecodeRequest(r, lzjson.Options{}).ParseError(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
+
+func TestDecodeRequest_emptyBody(t *testing.T) {
+	r1, _ := http.NewRequest("GET", "http://example.com/", nil)
+	r2 := httptest.NewRequest("GET", "/", nil)
+	r2.Body = http.NoBody
+	for _, r := range []*http.Request{r1, r2} {
+		r.Header.Set("Content-Type", "application/json")
+		if want, have := error(httpjson.ErrorEmptyBody), httpjson.DecodeRequest(r, lzjson.Options{}).ParseError(); want != have {
+			t.Errorf("body=%#v expected %#v, got %#v", r.Body, want, have)
+		}
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/html" {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+			return
+		}
+		httpjson.WriteNode(w, http.StatusOK, lzjson.ParseString(`{"hello": "world"}`))
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/json")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	n := httpjson.DecodeResponse(resp)
+	if err := n.ParseError(); err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+	if want, have := "world", n.Get("hello").String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+
+	resp, err = http.Get(server.URL + "/html")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if want, have := error(httpjson.ErrorContentType), httpjson.DecodeResponse(resp).ParseError(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
+
+func TestWriteNode(t *testing.T) {
+	tests := []struct {
+		Node lzjson.Node
+		Body string
+	}{
+		{lzjson.ParseString(`{"hello": "world"}`), `{"hello": "world"}`},
+		{lzjson.NewNode(), `null`},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		if err := httpjson.WriteNode(w, http.StatusCreated, test.Node); err != nil {
+			t.Errorf("unexpected error: %s", err.Error())
+			continue
+		}
+		if want, have := http.StatusCreated, w.Code; want != have {
+			t.Errorf("expected %d, got %d", want, have)
+		}
+		if want, have := "application/json; charset=utf-8", w.Header().Get("Content-Type"); want != have {
+			t.Errorf("expected %#v, got %#v", want, have)
+		}
+		if want, have := test.Body, w.Body.String(); want != have {
+			t.Errorf("expected %#v, got %#v", want, have)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	if err := httpjson.WriteNode(w, http.StatusOK, lzjson.ParseString(`{}`).Get("a").Get("b")); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	if b, _ := ioutil.ReadAll(w.Body); len(b) != 0 {
+		t.Errorf("expected nothing written, got %s", b)
+	}
+}
+
+func TestBodyError_Error(t *testing.T) {
+	if want, have := "unknown body error", httpjson.BodyError(-1).Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
@@ -0,0 +1,196 @@
+package httpjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-restit/lzjson"
+)
+
+// StatusError is an error to be responded with the status code
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+// Error implements error type
+func (err StatusError) Error() string {
+	return err.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (err StatusError) Unwrap() error {
+	return err.Err
+}
+
+// problemError is a member of the errors extension of Problem
+type problemError struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// problem is the RFC 9457 problem details object
+type problem struct {
+	Type   string         `json:"type"`
+	Title  string         `json:"title"`
+	Status int            `json:"status"`
+	Detail string         `json:"detail,omitempty"`
+	Errors []problemError `json:"errors,omitempty"`
+}
+
+// lzjsonErrors returns the lzjson.Error in err, or nil
+// if err does not wrap lzjson.Error nor lzjson.Errors
+func lzjsonErrors(err error) lzjson.Errors {
+	var errs lzjson.Errors
+	if errors.As(err, &errs) {
+		return errs
+	}
+	var e lzjson.Error
+	if errors.As(err, &e) {
+		return lzjson.Errors{e}
+	}
+	return nil
+}
+
+// StatusOf returns the status code to respond the error with,
+// looking into wrapped errors:
+//
+//   - the Status of StatusError;
+//   - 400 Bad Request for ErrorEmptyBody and ReadError;
+//   - 415 Unsupported Media Type for other BodyError;
+//   - 413 Content Too Large for ErrorLimitExceeded;
+//   - 400 Bad Request for malformed JSON;
+//   - 422 Unprocessable Content for other lzjson errors;
+//   - 500 Internal Server Error for anything else.
+func StatusOf(err error) int {
+	var serr StatusError
+	if errors.As(err, &serr) {
+		return serr.Status
+	}
+	var berr BodyError
+	if errors.As(err, &berr) {
+		if berr == ErrorEmptyBody {
+			return http.StatusBadRequest
+		}
+		return http.StatusUnsupportedMediaType
+	}
+	var rerr ReadError
+	var jerr *json.SyntaxError
+	switch {
+	case errors.As(err, &rerr):
+		return http.StatusBadRequest
+	case errors.Is(err, lzjson.ErrorLimitExceeded):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, lzjson.ErrorSyntax) || errors.As(err, &jerr):
+		return http.StatusBadRequest
+	case len(lzjsonErrors(err)) > 0:
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// Problem returns an RFC 9457 problem details Node of the status
+// and error. Every lzjson.Error in err is listed in the "errors"
+// extension with its path and reason:
+//
+//	{
+//	  "type": "about:blank",
+//	  "title": "Unprocessable Entity",
+//	  "status": 422,
+//	  "detail": "json.name: undefined",
+//	  "errors": [{"path": "json.name", "reason": "undefined"}]
+//	}
+//
+// For status 500 and above, the error is not disclosed.
+func Problem(status int, err error) lzjson.Node {
+	p := problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+	if err != nil && status < 500 {
+		errs := lzjsonErrors(err)
+		for _, e := range errs {
+			p.Errors = append(p.Errors, problemError{Path: e.Path, Reason: e.Err.Error()})
+		}
+		p.Detail = err.Error()
+		if len(errs) > 1 {
+			p.Detail = fmt.Sprintf("%d errors", len(errs))
+		}
+	}
+	n, _ := lzjson.FromValue(p)
+	return n
+}
+
+// WriteProblem writes the problem details of the error as
+// application/problem+json response, with the status
+// code given by StatusOf
+func WriteProblem(w http.ResponseWriter, err error) error {
+	status := StatusOf(err)
+	b, _ := Problem(status, err).MarshalJSON()
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_, err = w.Write(b)
+	return err
+}
+
+// HandlerFunc is an http handler that may fail with error
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP implements http.Handler. The error returned by the
+// function is responded with WriteProblem. The function should
+// not write to w if it returns an error
+func (fn HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := fn(w, r); err != nil {
+		WriteProblem(w, err)
+	}
+}
+
+// recoverable tells if the panic value is an error of lzjson
+// or of this package, which Middleware responds with
+func recoverable(v interface{}) (error, bool) {
+	err, ok := v.(error)
+	if !ok {
+		return nil, false
+	}
+	var (
+		lerr  lzjson.Error
+		lerrs lzjson.Errors
+		perr  lzjson.ParseError
+		berr  BodyError
+		rerr  ReadError
+		serr  StatusError
+	)
+	switch {
+	case errors.As(err, &lerrs), errors.As(err, &lerr), errors.As(err, &perr),
+		errors.As(err, &berr), errors.As(err, &rerr), errors.As(err, &serr):
+		return err, true
+	}
+	return nil, false
+}
+
+// Middleware responds with WriteProblem when the next handler
+// panics with an error of lzjson or of this package (e.g.
+// lzjson.Error, BodyError or StatusError), so that handlers
+// can abort with e.g. panic(n.Get("name").ParseError()).
+// Panics with other values, including runtime errors and
+// http.ErrAbortHandler, are passed on. The handler should
+// not write to w before panicking
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if err, ok := recoverable(v); ok {
+				WriteProblem(w, err)
+				return
+			}
+			panic(v)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
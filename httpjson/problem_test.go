@@ -0,0 +1,227 @@
+package httpjson_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-restit/lzjson"
+	"github.com/go-restit/lzjson/httpjson"
+)
+
+func TestStatusOf(t *testing.T) {
+	n := lzjson.ParseString(`{"name": 42}`)
+	truncated := httptest.NewRequest("POST", "/", bytes.NewReader(gzipped(`{"name": 42}`)[:20]))
+	truncated.Header.Set("Content-Type", "application/json")
+	truncated.Header.Set("Content-Encoding", "gzip")
+	badHeader := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": 42}`))
+	badHeader.Header.Set("Content-Type", "application/json")
+	badHeader.Header.Set("Content-Encoding", "gzip")
+	tests := []struct {
+		Err    error
+		Status int
+	}{
+		{httpjson.StatusError{Status: http.StatusConflict, Err: errors.New("conflict")}, http.StatusConflict},
+		{httpjson.ErrorContentType, http.StatusUnsupportedMediaType},
+		{httpjson.ErrorEmptyBody, http.StatusBadRequest},
+		{lzjson.Error{Path: "json", Err: lzjson.ErrorLimitExceeded}, http.StatusRequestEntityTooLarge},
+		{lzjson.ParseString(`{"name": }`).Get("name").ParseError(), http.StatusBadRequest},
+		{n.Get("email").ParseError(), http.StatusUnprocessableEntity},
+		{lzjson.Errors{{Path: "json.a", Err: lzjson.ErrorUndefined}}, http.StatusUnprocessableEntity},
+		{httpjson.DecodeRequest(truncated, lzjson.Options{}).ParseError(), http.StatusBadRequest},
+		{httpjson.DecodeRequest(badHeader, lzjson.Options{}).ParseError(), http.StatusBadRequest},
+		{fmt.Errorf("decode user: %w", n.Get("email").ParseError()), http.StatusUnprocessableEntity},
+		{fmt.Errorf("decode user: %w", lzjson.Errors{{Path: "json.a", Err: lzjson.ErrorUndefined}}), http.StatusUnprocessableEntity},
+		{fmt.Errorf("decode user: %w", httpjson.ErrorContentType), http.StatusUnsupportedMediaType},
+		{fmt.Errorf("decode user: %w", httpjson.StatusError{Status: http.StatusConflict, Err: errors.New("conflict")}), http.StatusConflict},
+		{errors.New("database down"), http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		if want, have := test.Status, httpjson.StatusOf(test.Err); want != have {
+			t.Errorf("err=%#v expected %d, got %d", test.Err, want, have)
+		}
+	}
+}
+
+func TestProblem(t *testing.T) {
+	tests := []struct {
+		Status int
+		Err    error
+		JSON   string
+	}{
+		{
+			http.StatusUnprocessableEntity,
+			lzjson.Error{Path: "json.name", Err: lzjson.ErrorUndefined},
+			`{"type": "about:blank", "title": "Unprocessable Entity", "status": 422,
+				"detail": "json.name: undefined",
+				"errors": [{"path": "json.name", "reason": "undefined"}]}`,
+		},
+		{
+			http.StatusUnprocessableEntity,
+			lzjson.Errors{
+				{Path: "json.name", Err: lzjson.ErrorUndefined},
+				{Path: "json.tags", Err: lzjson.ErrorNotArray},
+			},
+			`{"type": "about:blank", "title": "Unprocessable Entity", "status": 422,
+				"detail": "2 errors",
+				"errors": [{"path": "json.name", "reason": "undefined"}, {"path": "json.tags", "reason": "not an array"}]}`,
+		},
+		{
+			http.StatusUnsupportedMediaType,
+			httpjson.ErrorContentType,
+			`{"type": "about:blank", "title": "Unsupported Media Type", "status": 415,
+				"detail": "unsupported Content-Type"}`,
+		},
+		{
+			http.StatusUnprocessableEntity,
+			fmt.Errorf("decode user: %w", lzjson.Error{Path: "json.name", Err: lzjson.ErrorUndefined}),
+			`{"type": "about:blank", "title": "Unprocessable Entity", "status": 422,
+				"detail": "decode user: json.name: undefined",
+				"errors": [{"path": "json.name", "reason": "undefined"}]}`,
+		},
+		{
+			http.StatusInternalServerError,
+			errors.New("database password is 1234"),
+			`{"type": "about:blank", "title": "Internal Server Error", "status": 500}`,
+		},
+	}
+	for _, test := range tests {
+		have := httpjson.Problem(test.Status, test.Err)
+		if !lzjson.Equal(lzjson.ParseString(test.JSON), have) {
+			t.Errorf("expected %s, got %s", test.JSON, have.Raw())
+		}
+	}
+}
+
+func TestHandlerFunc(t *testing.T) {
+	handler := httpjson.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		n := httpjson.DecodeRequest(r, lzjson.Options{})
+		name := n.Get("name")
+		if err := name.ParseError(); err != nil {
+			return err
+		}
+		return httpjson.WriteNode(w, http.StatusOK, name)
+	})
+
+	tests := []struct {
+		Body        string
+		Status      int
+		ContentType string
+		JSON        string
+	}{
+		{`{"name": "John"}`, http.StatusOK, "application/json; charset=utf-8", `"John"`},
+		{
+			`{"email": "john@example.com"}`, http.StatusUnprocessableEntity, "application/problem+json",
+			`{"type": "about:blank", "title": "Unprocessable Entity", "status": 422,
+				"detail": "json.name: undefined",
+				"errors": [{"path": "json.name", "reason": "undefined"}]}`,
+		},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(test.Body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if want, have := test.Status, w.Code; want != have {
+			t.Errorf("body=%s expected %d, got %d", test.Body, want, have)
+		}
+		if want, have := test.ContentType, w.Header().Get("Content-Type"); want != have {
+			t.Errorf("body=%s expected %#v, got %#v", test.Body, want, have)
+		}
+		if have := lzjson.ParseString(w.Body.String()); !lzjson.Equal(lzjson.ParseString(test.JSON), have) {
+			t.Errorf("body=%s expected %s, got %s", test.Body, test.JSON, have.Raw())
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	handler := httpjson.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := httpjson.DecodeRequest(r, lzjson.Options{})
+		name := n.Get("name")
+		if err := name.ParseError(); err != nil {
+			panic(err)
+		}
+		httpjson.WriteNode(w, http.StatusOK, name)
+	}))
+
+	tests := []struct {
+		Body        string
+		Status      int
+		ContentType string
+		JSON        string
+	}{
+		{`{"name": "John"}`, http.StatusOK, "application/json; charset=utf-8", `"John"`},
+		{
+			`{"email": "john@example.com"}`, http.StatusUnprocessableEntity, "application/problem+json",
+			`{"type": "about:blank", "title": "Unprocessable Entity", "status": 422,
+				"detail": "json.name: undefined",
+				"errors": [{"path": "json.name", "reason": "undefined"}]}`,
+		},
+		{
+			`{"name": }`, http.StatusBadRequest, "application/problem+json",
+			`{"type": "about:blank", "title": "Bad Request", "status": 400,
+				"detail": "json.name: syntax error (json: invalid character '}' looking for beginning of value at offset 10)",
+				"errors": [{"path": "json", "reason": "invalid character '}' looking for beginning of value at offset 10"}]}`,
+		},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(test.Body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if want, have := test.Status, w.Code; want != have {
+			t.Errorf("body=%s expected %d, got %d", test.Body, want, have)
+		}
+		if want, have := test.ContentType, w.Header().Get("Content-Type"); want != have {
+			t.Errorf("body=%s expected %#v, got %#v", test.Body, want, have)
+		}
+		if have := lzjson.ParseString(w.Body.String()); !lzjson.Equal(lzjson.ParseString(test.JSON), have) {
+			t.Errorf("body=%s expected %s, got %s", test.Body, test.JSON, have.Raw())
+		}
+	}
+}
+
+func TestMiddleware_panic(t *testing.T) {
+	tests := []struct {
+		Name string
+		Fn   func()
+	}{
+		{"string", func() { panic("not an error") }},
+		{"error", func() { panic(errors.New("database down")) }},
+		{"runtime error", func() {
+			var m map[string]int
+			m["a"] = 1
+		}},
+		{"abort", func() { panic(http.ErrAbortHandler) }},
+	}
+	for _, test := range tests {
+		handler := httpjson.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			test.Fn()
+		}))
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected the panic to be passed on", test.Name)
+				}
+			}()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		}()
+	}
+}
+
+func TestMiddleware_wrapped(t *testing.T) {
+	handler := httpjson.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(fmt.Errorf("create user: %w", httpjson.StatusError{Status: http.StatusConflict, Err: errors.New("user exists")}))
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if want, have := http.StatusConflict, w.Code; want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+}
@@ -0,0 +1,226 @@
+package lzjson
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Shape describes the structure of JSON values, inferred
+// from samples by InferSchema
+type Shape struct {
+	// Types are the types of the values, in the order first seen.
+	// TypeNull is not included but reflected by Nullable
+	Types []Type
+
+	// Nullable tells if any of the values is null
+	Nullable bool
+
+	// Integer tells if all the numbers are written
+	// as integers (i.e. without fraction or exponent)
+	Integer bool
+
+	// Fields are the members of the object values,
+	// in the order first seen
+	Fields []*Field
+
+	// Items describes all the items of the array values.
+	// It is nil if no array item was seen
+	Items *Shape
+
+	// Count is the number of values merged into the shape
+	Count int
+
+	numbers int // number of number values seen
+	objects int // number of object values seen
+}
+
+// Field describes a member of the object values of a Shape
+type Field struct {
+	Name string
+
+	// Optional tells if the member is missing in
+	// any of the object values
+	Optional bool
+
+	Shape *Shape
+
+	seen int // number of object values having the member
+}
+
+// InferSchema walks the samples and infers a merged Shape
+// of them. Samples that are undefined, have parse error or
+// malformed raw JSON are skipped.
+func InferSchema(samples ...Node) *Shape {
+	s := &Shape{}
+	for _, n := range samples {
+		if b, err := rawValue(n); err == nil && len(b) > 0 {
+			s.merge(b)
+		}
+	}
+	return s
+}
+
+// isIntText tells if the validated raw JSON number
+// is written without fraction or exponent
+func isIntText(b []byte) bool {
+	return bytes.IndexAny(b, ".eE") < 0
+}
+
+// addType adds the type to the shape, if not already there
+func (s *Shape) addType(t Type) {
+	for _, typ := range s.Types {
+		if typ == t {
+			return
+		}
+	}
+	s.Types = append(s.Types, t)
+}
+
+// field returns the field of the name, or nil if not exists
+func (s *Shape) field(name string) *Field {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// merge merges the validated raw JSON value into the shape
+func (s *Shape) merge(b []byte) {
+	s.Count++
+	switch {
+	case b[0] == 'n':
+		s.Nullable = true
+	case b[0] == 't' || b[0] == 'f':
+		s.addType(TypeBool)
+	case b[0] == '"':
+		s.addType(TypeString)
+	case isNumStart(b[0]):
+		s.addType(TypeNumber)
+		s.Integer = (s.numbers == 0 || s.Integer) && isIntText(b)
+		s.numbers++
+	case b[0] == '[':
+		s.addType(TypeArray)
+		elems, _, _ := scanArray(b, 0)
+		for _, e := range elems {
+			if s.Items == nil {
+				s.Items = &Shape{}
+			}
+			s.Items.merge(b[e.start:e.end])
+		}
+	case b[0] == '{':
+		s.addType(TypeObject)
+		s.objects++
+		members, _, _ := scanObject(b, 0)
+		for i, m := range members {
+			if lastMember(members, m.key) != i {
+				continue // overridden by duplicated key
+			}
+			f := s.field(m.key)
+			if f == nil {
+				f = &Field{Name: m.key, Shape: &Shape{}}
+				s.Fields = append(s.Fields, f)
+			}
+			f.seen++
+			f.Shape.merge(b[m.valStart:m.valEnd])
+		}
+		for _, f := range s.Fields {
+			f.Optional = f.seen < s.objects
+		}
+	}
+}
+
+// schemaTypeName returns the JSON Schema type name of the Type
+func (s *Shape) schemaTypeName(t Type) string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeNumber:
+		if s.Integer {
+			return "integer"
+		}
+		return "number"
+	case TypeObject:
+		return "object"
+	case TypeArray:
+		return "array"
+	case TypeBool:
+		return "boolean"
+	}
+	return "null"
+}
+
+// JSONSchema exports the shape as a JSON Schema (draft 2020-12)
+func (s *Shape) JSONSchema() Node {
+	var buf bytes.Buffer
+	buf.WriteString(`{"$schema":"https://json-schema.org/draft/2020-12/schema"`)
+	s.writeSchema(&buf, true)
+	buf.WriteByte('}')
+	return Parse(buf.Bytes())
+}
+
+// writeSchema writes the keywords of the shape's schema
+// into buf. The opening and closing braces are not written
+func (s *Shape) writeSchema(buf *bytes.Buffer, hasPrev bool) {
+	write := func(key string, value []byte) {
+		if hasPrev {
+			buf.WriteByte(',')
+		}
+		hasPrev = true
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	types := make([]string, 0, len(s.Types)+1)
+	for _, t := range s.Types {
+		types = append(types, s.schemaTypeName(t))
+	}
+	if s.Nullable {
+		types = append(types, "null")
+	}
+	switch len(types) {
+	case 0:
+	case 1:
+		v, _ := json.Marshal(types[0])
+		write("type", v)
+	default:
+		v, _ := json.Marshal(types)
+		write("type", v)
+	}
+
+	if len(s.Fields) > 0 {
+		var props bytes.Buffer
+		props.WriteByte('{')
+		required := []string{}
+		for i, f := range s.Fields {
+			if i > 0 {
+				props.WriteByte(',')
+			}
+			k, _ := json.Marshal(f.Name)
+			props.Write(k)
+			props.WriteString(":{")
+			f.Shape.writeSchema(&props, false)
+			props.WriteByte('}')
+			if !f.Optional {
+				required = append(required, f.Name)
+			}
+		}
+		props.WriteByte('}')
+		write("properties", props.Bytes())
+		if len(required) > 0 {
+			v, _ := json.Marshal(required)
+			write("required", v)
+		}
+	}
+
+	if s.Items != nil {
+		var items bytes.Buffer
+		items.WriteByte('{')
+		s.Items.writeSchema(&items, false)
+		items.WriteByte('}')
+		write("items", items.Bytes())
+	}
+}
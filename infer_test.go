@@ -0,0 +1,104 @@
+package lzjson_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+func TestInferSchema(t *testing.T) {
+	samples := []lzjson.Node{
+		lzjson.ParseString(`{"id": 1, "name": "John", "tags": ["a"], "address": {"city": "Hong Kong"}}`),
+		lzjson.ParseString(`{"id": 2, "name": null, "score": 4.5, "tags": [], "address": {"city": "Tokyo", "zip": "100"}}`),
+		lzjson.ParseString(`{"id": 3, "name": "Mary", "score": 3, "tags": ["b", 1]}`),
+	}
+	shape := lzjson.InferSchema(samples...)
+
+	if want, have := 3, shape.Count; want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+	names := []string{}
+	for _, f := range shape.Fields {
+		names = append(names, f.Name)
+	}
+	if want, have := "[id name tags address score]", fmt.Sprint(names); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+
+	have := shape.JSONSchema()
+	want := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer"},
+			"name": {"type": ["string", "null"]},
+			"tags": {"type": "array", "items": {"type": ["string", "integer"]}},
+			"address": {
+				"type": "object",
+				"properties": {"city": {"type": "string"}, "zip": {"type": "string"}},
+				"required": ["city"]
+			},
+			"score": {"type": "number"}
+		},
+		"required": ["id", "name", "tags"]
+	}`
+	if !lzjson.Equal(lzjson.ParseString(want), have) {
+		t.Errorf("expected %s, got %s", want, have.Raw())
+	}
+
+	// the inferred schema validates all the samples
+	schema, err := lzjson.CompileSchema(have)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	for i, sample := range samples {
+		if err := schema.Validate(sample); err != nil {
+			t.Errorf("sample %d: unexpected error: %s", i, err.Error())
+		}
+	}
+}
+
+func TestInferSchema_types(t *testing.T) {
+	tests := []struct {
+		Samples []string
+		Schema  string
+	}{
+		{[]string{}, `{}`},
+		{[]string{`null`}, `{"type": "null"}`},
+		{[]string{`true`, `false`}, `{"type": "boolean"}`},
+		{[]string{`1`, `2`}, `{"type": "integer"}`},
+		{[]string{`1`, `2.0`}, `{"type": "number"}`},
+		{[]string{`1e3`}, `{"type": "number"}`},
+		{[]string{`"a"`, `1`, `null`}, `{"type": ["string", "integer", "null"]}`},
+		{[]string{`[]`}, `{"type": "array"}`},
+		{[]string{`[[1], [null]]`}, `{"type": "array", "items": {"type": "array", "items": {"type": ["integer", "null"]}}}`},
+		{[]string{`{}`, `{"a": 1}`}, `{"type": "object", "properties": {"a": {"type": "integer"}}}`},
+		{[]string{`{"a": 1, "a": "x"}`}, `{"type": "object", "properties": {"a": {"type": "string"}}, "required": ["a"]}`},
+		{[]string{`{"a": 1}`, `[1]`}, `{"type": ["object", "array"], "properties": {"a": {"type": "integer"}}, "required": ["a"], "items": {"type": "integer"}}`},
+	}
+	for _, test := range tests {
+		samples := make([]lzjson.Node, len(test.Samples))
+		for i, str := range test.Samples {
+			samples[i] = lzjson.ParseString(str)
+		}
+		have, _ := lzjson.InferSchema(samples...).JSONSchema().Delete("$schema")
+		if !lzjson.Equal(lzjson.ParseString(test.Schema), have) {
+			t.Errorf("samples=%v expected %s, got %s", test.Samples, test.Schema, have.Raw())
+		}
+	}
+}
+
+func TestInferSchema_skip(t *testing.T) {
+	shape := lzjson.InferSchema(
+		lzjson.ParseString(`{"a": 1}`),
+		lzjson.ParseString(`{"a": 1}`).Get("b"),
+		lzjson.ParseString(`{"a": `),
+	)
+	if want, have := 1, shape.Count; want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+	if want, have := false, shape.Fields[0].Optional; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
@@ -0,0 +1,607 @@
+package lzjson
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// NonFinite is the policy for the non-finite numbers
+// (Infinity, -Infinity and NaN) of JSON5, which have
+// no representation in JSON. The policy is applied when
+// Raw, Unmarshal or MarshalJSON is called on a value
+// containing such numbers, so other values of the same
+// document are not affected
+type NonFinite int
+
+// policies of non-finite numbers
+const (
+	// NonFiniteError fails Unmarshal and MarshalJSON with
+	// an Error of ErrorNonFinite at the path of the number.
+	// Raw returns the number as written (e.g. `Infinity`),
+	// which is not valid JSON
+	NonFiniteError NonFinite = iota
+
+	// NonFiniteNull converts the numbers to null
+	NonFiniteNull
+
+	// NonFiniteString converts the numbers to strings
+	// "Infinity", "-Infinity" and "NaN"
+	NonFiniteString
+)
+
+// nonFiniteError describes a non-finite number
+// refused by NonFiniteError
+type nonFiniteError struct {
+	text   string
+	offset int
+}
+
+// Error implements error type
+func (err nonFiniteError) Error() string {
+	return fmt.Sprintf("non-finite number %s at offset %d", err.text, err.offset)
+}
+
+// Is tells if the target is ErrorNonFinite, for errors.Is
+func (err nonFiniteError) Is(target error) bool {
+	return target == ErrorNonFinite
+}
+
+// nonFinitePrefix starts the placeholder string of a non-finite
+// number in the converted JSON5, e.g. `"\uFFFFInfinity 8"` for
+// Infinity at offset 8. Strings of the input are never written
+// with such escape, so placeholders are told apart from them
+const nonFinitePrefix = `"\uFFFF`
+
+// parseNonFinite returns the text and offset of the non-finite
+// number of the raw placeholder string, or false if b is not one
+func parseNonFinite(b []byte) (text string, offset int, ok bool) {
+	if !bytes.HasPrefix(b, []byte(nonFinitePrefix)) || b[len(b)-1] != '"' {
+		return
+	}
+	_, err := fmt.Sscanf(string(b[len(nonFinitePrefix):len(b)-1]), "%s %d", &text, &offset)
+	return text, offset, err == nil
+}
+
+// replaceNonFinite returns a copy of the validated raw JSON value
+// at the path with the placeholders of non-finite numbers replaced
+// by what fn returns. b is returned as-is if it has no placeholder
+func replaceNonFinite(b []byte, path string, fn func(text string, offset int, path string) ([]byte, error)) ([]byte, error) {
+	if !bytes.Contains(b, []byte(nonFinitePrefix)) {
+		return b, nil
+	}
+	var out bytes.Buffer
+	var walk func(b []byte, path string) error
+	walk = func(b []byte, path string) error {
+		last := 0
+		switch b[0] {
+		case '"':
+			if text, offset, ok := parseNonFinite(b); ok {
+				v, err := fn(text, offset, path)
+				out.Write(v)
+				return err
+			}
+		case '{':
+			members, _, _ := scanObject(b, 0)
+			for _, m := range members {
+				out.Write(b[last:m.valStart])
+				if err := walk(b[m.valStart:m.valEnd], keyPath(path, m.key)); err != nil {
+					return err
+				}
+				last = m.valEnd
+			}
+		case '[':
+			elems, _, _ := scanArray(b, 0)
+			for i, e := range elems {
+				out.Write(b[last:e.start])
+				if err := walk(b[e.start:e.end], nthPath(path, i)); err != nil {
+					return err
+				}
+				last = e.end
+			}
+		}
+		out.Write(b[last:])
+		return nil
+	}
+	start := skipSpace(b, 0)
+	if start == len(b) {
+		return b, nil
+	}
+	end, _ := scanValue(b, start)
+	out.Write(b[:start])
+	if err := walk(b[start:end], path); err != nil {
+		return nil, err
+	}
+	out.Write(b[end:])
+	return out.Bytes(), nil
+}
+
+// json5 tells if the node holds converted JSON5 input,
+// which may have placeholders of non-finite numbers
+func (n *rootNode) json5() bool {
+	return n.limits != nil && n.limits.Syntax == SyntaxJSON5
+}
+
+// resolvedRaw returns the raw JSON of the node with the non-finite
+// numbers converted as the NonFinite policy tells. With NonFiniteError,
+// an Error of ErrorNonFinite at the first of them is returned instead
+func (n *rootNode) resolvedRaw() ([]byte, error) {
+	if !n.json5() {
+		return n.buf, nil
+	}
+	policy := n.limits.NonFinite
+	return replaceNonFinite(n.buf, n.path, func(text string, offset int, path string) ([]byte, error) {
+		switch policy {
+		case NonFiniteNull:
+			return []byte("null"), nil
+		case NonFiniteString:
+			return []byte(`"` + text + `"`), nil
+		}
+		return nil, Error{Path: "json" + path, Err: nonFiniteError{text, offset}}
+	})
+}
+
+// nonFiniteValue returns the value of the node if it is
+// a non-finite number of JSON5 input
+func (n *rootNode) nonFiniteValue() (float64, bool) {
+	if !n.json5() {
+		return 0, false
+	}
+	text, _, ok := parseNonFinite(bytes.TrimSpace(n.buf))
+	switch {
+	case !ok:
+		return 0, false
+	case text == "NaN":
+		return math.NaN(), true
+	case text == "-Infinity":
+		return math.Inf(-1), true
+	}
+	return math.Inf(1), true
+}
+
+// json5Parser converts JSON5 input into strict JSON
+type json5Parser struct {
+	in  []byte
+	pos int
+	out bytes.Buffer
+}
+
+// fromJSON5 converts the JSON5 input into compact strict JSON, with
+// non-finite numbers as placeholders. Offsets in the returned errors
+// are of the input. An input with no value (e.g. only comments)
+// converts to empty bytes
+func fromJSON5(b []byte) ([]byte, error) {
+	p := &json5Parser{in: b}
+	if err := p.skip(); err != nil {
+		return nil, err
+	}
+	if p.pos == len(p.in) {
+		return []byte{}, nil
+	}
+	if err := p.value(); err != nil {
+		return nil, err
+	}
+	if err := p.skip(); err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.in) {
+		return nil, p.unexpected()
+	}
+	return p.out.Bytes(), nil
+}
+
+// unexpected returns a syntaxError for the character at pos
+func (p *json5Parser) unexpected() error {
+	if p.pos >= len(p.in) {
+		return syntaxError{"unexpected end of JSON5 input", p.pos}
+	}
+	r, _ := utf8.DecodeRune(p.in[p.pos:])
+	return syntaxError{fmt.Sprintf("invalid character %q", r), p.pos}
+}
+
+// peek returns the rune at pos and its size, or
+// utf8.RuneError and 0 at the end of input
+func (p *json5Parser) peek() (rune, int) {
+	return utf8.DecodeRune(p.in[p.pos:])
+}
+
+// isJSON5Space tells if the rune is a JSON5 whitespace
+func isJSON5Space(r rune) bool {
+	switch r {
+	case '\t', '\n', '\v', '\f', '\r', ' ', '\u00a0', '\u2028', '\u2029', '\ufeff':
+		return true
+	}
+	return unicode.Is(unicode.Zs, r)
+}
+
+// isLineTerminator tells if the rune is a JSON5 line terminator
+func isLineTerminator(r rune) bool {
+	return r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029'
+}
+
+// skip skips the whitespace and comments at pos
+func (p *json5Parser) skip() error {
+	for p.pos < len(p.in) {
+		r, size := p.peek()
+		switch {
+		case isJSON5Space(r):
+			p.pos += size
+		case bytes.HasPrefix(p.in[p.pos:], []byte("//")):
+			for p.pos < len(p.in) {
+				r, size := p.peek()
+				if isLineTerminator(r) {
+					break
+				}
+				p.pos += size
+			}
+		case bytes.HasPrefix(p.in[p.pos:], []byte("/*")):
+			end := bytes.Index(p.in[p.pos+2:], []byte("*/"))
+			if end < 0 {
+				return syntaxError{"unterminated comment", p.pos}
+			}
+			p.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// value converts the value at pos
+func (p *json5Parser) value() error {
+	if p.pos >= len(p.in) {
+		return p.unexpected()
+	}
+	switch c := p.in[p.pos]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"' || c == '\'':
+		str, err := p.string()
+		if err != nil {
+			return err
+		}
+		writeCanonicalString(&p.out, str)
+		return nil
+	case c == '+' || c == '-' || c == '.' || c == 'I' || c == 'N' || (c >= '0' && c <= '9'):
+		return p.number()
+	}
+	for _, lit := range []string{"true", "false", "null"} {
+		if bytes.HasPrefix(p.in[p.pos:], []byte(lit)) {
+			p.pos += len(lit)
+			p.out.WriteString(lit)
+			return nil
+		}
+	}
+	return p.unexpected()
+}
+
+// object converts the object at pos
+func (p *json5Parser) object() error {
+	p.pos++
+	p.out.WriteByte('{')
+	for i := 0; ; i++ {
+		if err := p.skip(); err != nil {
+			return err
+		}
+		if p.pos < len(p.in) && p.in[p.pos] == '}' {
+			break // empty object or trailing comma
+		}
+		if i > 0 {
+			p.out.WriteByte(',')
+		}
+		key, err := p.key()
+		if err != nil {
+			return err
+		}
+		writeCanonicalString(&p.out, key)
+		if err := p.skip(); err != nil {
+			return err
+		}
+		if p.pos >= len(p.in) || p.in[p.pos] != ':' {
+			return p.unexpected()
+		}
+		p.pos++
+		p.out.WriteByte(':')
+		if err := p.skip(); err != nil {
+			return err
+		}
+		if err := p.value(); err != nil {
+			return err
+		}
+		if err := p.skip(); err != nil {
+			return err
+		}
+		if p.pos < len(p.in) && p.in[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if p.pos >= len(p.in) || p.in[p.pos] != '}' {
+			return p.unexpected()
+		}
+		break
+	}
+	p.pos++
+	p.out.WriteByte('}')
+	return nil
+}
+
+// array converts the array at pos
+func (p *json5Parser) array() error {
+	p.pos++
+	p.out.WriteByte('[')
+	for i := 0; ; i++ {
+		if err := p.skip(); err != nil {
+			return err
+		}
+		if p.pos < len(p.in) && p.in[p.pos] == ']' {
+			break // empty array or trailing comma
+		}
+		if i > 0 {
+			p.out.WriteByte(',')
+		}
+		if err := p.value(); err != nil {
+			return err
+		}
+		if err := p.skip(); err != nil {
+			return err
+		}
+		if p.pos < len(p.in) && p.in[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if p.pos >= len(p.in) || p.in[p.pos] != ']' {
+			return p.unexpected()
+		}
+		break
+	}
+	p.pos++
+	p.out.WriteByte(']')
+	return nil
+}
+
+// key returns the member name at pos, which is
+// either a string or an ECMAScript 5.1 IdentifierName
+func (p *json5Parser) key() (string, error) {
+	if p.pos < len(p.in) && (p.in[p.pos] == '"' || p.in[p.pos] == '\'') {
+		return p.string()
+	}
+	var name []rune
+	for p.pos < len(p.in) {
+		start := p.pos
+		r, size := p.peek()
+		if r == '\\' {
+			// unicode escape sequence, e.g. \u0041
+			if p.pos+1 >= len(p.in) || p.in[p.pos+1] != 'u' {
+				p.pos++
+				return "", p.unexpected()
+			}
+			p.pos += 2
+			var err error
+			if r, err = p.hex(4); err != nil {
+				return "", err
+			}
+		} else {
+			p.pos += size
+		}
+		if !isIdentStart(r) && (len(name) == 0 || !isIdentPart(r)) {
+			p.pos = start
+			break
+		}
+		name = append(name, r)
+	}
+	if len(name) == 0 {
+		return "", p.unexpected()
+	}
+	return string(name), nil
+}
+
+// isIdentStart tells if the rune may start an IdentifierName
+func isIdentStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.In(r, unicode.Lu, unicode.Ll, unicode.Lt, unicode.Lm, unicode.Lo, unicode.Nl)
+}
+
+// isIdentPart tells if the rune may be a non-first
+// character of an IdentifierName
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || r == '\u200c' || r == '\u200d' ||
+		unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc)
+}
+
+// hex reads n hexadecimal digits at pos as a rune
+func (p *json5Parser) hex(n int) (rune, error) {
+	var r rune
+	for i := 0; i < n; i++ {
+		if p.pos >= len(p.in) || !isHex(p.in[p.pos]) {
+			return 0, p.unexpected()
+		}
+		c := p.in[p.pos]
+		switch {
+		case c >= 'a':
+			c -= 'a' - 10
+		case c >= 'A':
+			c -= 'A' - 10
+		default:
+			c -= '0'
+		}
+		r = r<<4 | rune(c)
+		p.pos++
+	}
+	return r, nil
+}
+
+// string returns the decoded single or double quoted string at pos
+func (p *json5Parser) string() (string, error) {
+	quote := rune(p.in[p.pos])
+	p.pos++
+	var str []rune
+	for {
+		if p.pos >= len(p.in) {
+			return "", p.unexpected()
+		}
+		r, size := p.peek()
+		switch {
+		case r == quote:
+			p.pos++
+			return string(str), nil
+		case r == '\n' || r == '\r':
+			return "", p.unexpected()
+		case r != '\\':
+			p.pos += size
+			str = append(str, r)
+			continue
+		}
+
+		// escape sequence
+		p.pos++
+		if p.pos >= len(p.in) {
+			return "", p.unexpected()
+		}
+		r, size = p.peek()
+		switch r {
+		case 'b':
+			r = '\b'
+		case 'f':
+			r = '\f'
+		case 'n':
+			r = '\n'
+		case 'r':
+			r = '\r'
+		case 't':
+			r = '\t'
+		case 'v':
+			r = '\v'
+		case '0':
+			if p.pos+1 < len(p.in) && p.in[p.pos+1] >= '0' && p.in[p.pos+1] <= '9' {
+				p.pos++
+				return "", p.unexpected()
+			}
+			r = 0
+		case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			return "", p.unexpected()
+		case 'x', 'u':
+			p.pos++
+			n := 2
+			if r == 'u' {
+				n = 4
+			}
+			var err error
+			if r, err = p.hex(n); err != nil {
+				return "", err
+			}
+			if utf16.IsSurrogate(r) && bytes.HasPrefix(p.in[p.pos:], []byte(`\u`)) {
+				// combine a surrogate pair
+				pos := p.pos
+				p.pos += 2
+				if r2, err := p.hex(4); err == nil && utf16.DecodeRune(r, r2) != unicode.ReplacementChar {
+					r = utf16.DecodeRune(r, r2)
+				} else {
+					p.pos = pos
+				}
+			}
+			str = append(str, r)
+			continue
+		case '\r':
+			// line continuation of CRLF or CR
+			p.pos++
+			if p.pos < len(p.in) && p.in[p.pos] == '\n' {
+				p.pos++
+			}
+			continue
+		case '\n', '\u2028', '\u2029':
+			// line continuation
+			p.pos += size
+			continue
+		}
+		p.pos += size
+		str = append(str, r)
+	}
+}
+
+// number converts the number at pos
+func (p *json5Parser) number() error {
+	start := p.pos
+	sign := ""
+	if c := p.in[p.pos]; c == '+' || c == '-' {
+		if c == '-' {
+			sign = "-"
+		}
+		p.pos++
+	}
+
+	rest := p.in[p.pos:]
+	switch {
+	case bytes.HasPrefix(rest, []byte("Infinity")):
+		p.pos += len("Infinity")
+		return p.writeNonFinite(sign+"Infinity", start)
+	case bytes.HasPrefix(rest, []byte("NaN")):
+		p.pos += len("NaN")
+		return p.writeNonFinite("NaN", start)
+	case bytes.HasPrefix(rest, []byte("0x")) || bytes.HasPrefix(rest, []byte("0X")):
+		p.pos += 2
+		digits := p.pos
+		for p.pos < len(p.in) && isHex(p.in[p.pos]) {
+			p.pos++
+		}
+		if p.pos == digits {
+			return p.unexpected()
+		}
+		v, _ := new(big.Int).SetString(string(p.in[digits:p.pos]), 16)
+		p.out.WriteString(sign + v.String())
+		return nil
+	}
+
+	// decimal number, with optional integer or fraction part
+	intStart := p.pos
+	if p.pos < len(p.in) && p.in[p.pos] == '0' {
+		p.pos++
+		if p.pos < len(p.in) && p.in[p.pos] >= '0' && p.in[p.pos] <= '9' {
+			return p.unexpected() // no octal or leading zeros
+		}
+	} else {
+		p.pos = scanDigits(p.in, p.pos)
+	}
+	intPart := string(p.in[intStart:p.pos])
+	var fracPart string
+	if p.pos < len(p.in) && p.in[p.pos] == '.' {
+		p.pos++
+		fracStart := p.pos
+		p.pos = scanDigits(p.in, p.pos)
+		fracPart = string(p.in[fracStart:p.pos])
+	}
+	if intPart == "" && fracPart == "" {
+		return p.unexpected()
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	p.out.WriteString(sign + intPart)
+	if fracPart != "" {
+		p.out.WriteString("." + fracPart)
+	}
+	if p.pos < len(p.in) && (p.in[p.pos] == 'e' || p.in[p.pos] == 'E') {
+		expStart := p.pos
+		p.pos++
+		if p.pos < len(p.in) && (p.in[p.pos] == '+' || p.in[p.pos] == '-') {
+			p.pos++
+		}
+		digits := p.pos
+		if p.pos = scanDigits(p.in, p.pos); p.pos == digits {
+			return p.unexpected()
+		}
+		p.out.Write(p.in[expStart:p.pos])
+	}
+	return nil
+}
+
+// writeNonFinite writes the placeholder of the
+// non-finite number of the text at the offset
+func (p *json5Parser) writeNonFinite(text string, offset int) error {
+	fmt.Fprintf(&p.out, "%s%s %d\"", nonFinitePrefix, text, offset)
+	return nil
+}
@@ -0,0 +1,192 @@
+package lzjson_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+func TestDecodeWithOptions_json5Fixtures(t *testing.T) {
+	opts := lzjson.Options{Syntax: lzjson.SyntaxJSON5, NonFinite: lzjson.NonFiniteString}
+	count := 0
+	err := filepath.Walk(filepath.Join("testdata", "json5"), func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || info.Name() == "README.md" {
+			return err
+		}
+		count++
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		n := lzjson.DecodeWithOptions(strings.NewReader(string(b)), opts)
+		err = lzjson.Validate(n)
+
+		switch filepath.Ext(path) {
+		case ".json", ".json5":
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", path, err)
+			}
+		default:
+			if err == nil {
+				t.Errorf("%s: expected error, got nil (%s)", path, n.Raw())
+			}
+		}
+
+		// valid JSON decodes the same as encoding/json does
+		if filepath.Ext(path) == ".json" && err == nil {
+			var want, have interface{}
+			if err := json.Unmarshal(b, &want); err != nil {
+				t.Errorf("%s: unexpected error: %s", path, err)
+			} else if err := n.Unmarshal(&have); err != nil {
+				t.Errorf("%s: unexpected error: %s", path, err)
+			} else if !reflect.DeepEqual(want, have) {
+				t.Errorf("%s: expected %#v, got %#v", path, want, have)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if count == 0 {
+		t.Errorf("no fixture found")
+	}
+}
+
+func TestDecodeWithOptions_json5(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{unquoted: 'single \'quoted\'', "double": "\x41é😀"}`, `{"unquoted":"single 'quoted'","double":"Aé😀"}`},
+		{`{$_a1: 1, ab: 2, 'while': 3}`, `{"$_a1":1,"ab":2,"while":3}`},
+		{`[0x1F, -0xff, +1, .5, 5., 5.e3, -0.0, 0xFFFFFFFFFFFFFFFFFF]`, `[31,-255,1,0.5,5,5e3,-0.0,4722366482869645213695]`},
+		{"'line \\\r\ncontinued\\0 \\v'", `"line continued\u0000 \u000b"`},
+		{"// only a comment\n[1, /* two */ 2,]", `[1,2]`},
+		{"\ufeff\u00a0{\u2028}", `{}`},
+		{"/* nothing */", ``},
+	}
+	for _, test := range tests {
+		n := lzjson.DecodeWithOptions(strings.NewReader(test.input), lzjson.Options{Syntax: lzjson.SyntaxJSON5})
+		if err := n.ParseError(); err != nil {
+			t.Errorf("%#v: unexpected error: %s", test.input, err)
+		} else if want, have := test.want, string(n.Raw()); want != have {
+			t.Errorf("%#v: expected %#v, got %#v", test.input, want, have)
+		}
+	}
+}
+
+func TestDecodeWithOptions_json5NonFinite(t *testing.T) {
+	input := `{a: [1, -Infinity], b: +Infinity, c: NaN, d: "ok"}`
+	tests := []struct {
+		policy lzjson.NonFinite
+		want   string
+		typ    lzjson.Type
+	}{
+		{lzjson.NonFiniteNull, `{"a":[1,null],"b":null,"c":null,"d":"ok"}`, lzjson.TypeNull},
+		{lzjson.NonFiniteString, `{"a":[1,"-Infinity"],"b":"Infinity","c":"NaN","d":"ok"}`, lzjson.TypeString},
+		{lzjson.NonFiniteError, `{"a":[1,-Infinity],"b":Infinity,"c":NaN,"d":"ok"}`, lzjson.TypeNumber},
+	}
+	for _, test := range tests {
+		n := lzjson.DecodeWithOptions(strings.NewReader(input), lzjson.Options{Syntax: lzjson.SyntaxJSON5, NonFinite: test.policy})
+		if err := n.ParseError(); err != nil {
+			t.Errorf("policy=%d unexpected error: %s", test.policy, err)
+		}
+		if want, have := test.want, string(n.Raw()); want != have {
+			t.Errorf("policy=%d expected %#v, got %#v", test.policy, want, have)
+		}
+		if want, have := test.typ, n.Get("b").Type(); want != have {
+			t.Errorf("policy=%d expected %s, got %s", test.policy, want, have)
+		}
+		if have := n.Get("b").Number(); !math.IsInf(have, 1) {
+			t.Errorf("policy=%d expected +Inf, got %v", test.policy, have)
+		}
+		if have := n.Get("c").Number(); !math.IsNaN(have) {
+			t.Errorf("policy=%d expected NaN, got %v", test.policy, have)
+		}
+	}
+
+	// refused by default, only when read
+	n := lzjson.DecodeWithOptions(strings.NewReader(input), lzjson.Options{Syntax: lzjson.SyntaxJSON5})
+	if err := n.ParseError(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var v interface{}
+	if err := n.Get("d").Unmarshal(&v); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if want, have := "ok", v; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	_, merr := n.MarshalJSON()
+	errs := []struct {
+		err  error
+		want string
+	}{
+		{n.Unmarshal(&v), "json.a[1]: non-finite number -Infinity at offset 8"},
+		{n.Get("b").Unmarshal(&v), "json.b: non-finite number Infinity at offset 23"},
+		{lzjson.Validate(n.Get("c")), "json.c: non-finite number NaN at offset 37"},
+		{merr, "json.a[1]: non-finite number -Infinity at offset 8"},
+		{n.Canonical().ParseError(), "json.a[1]: non-finite number -Infinity at offset 8"},
+	}
+	for _, test := range errs {
+		if test.err == nil {
+			t.Errorf("expected error %#v, got nil", test.want)
+			continue
+		}
+		if want, have := test.want, test.err.Error(); want != have {
+			t.Errorf("expected %#v, got %#v", want, have)
+		}
+		if !errors.Is(test.err, lzjson.ErrorNonFinite) {
+			t.Errorf("expected ErrorNonFinite, got %#v", test.err)
+		}
+	}
+
+	// edited nodes keep the policy
+	edited, err := n.Set("d", "edited")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want, have := `{"a":[1,-Infinity],"b":Infinity,"c":NaN,"d":"edited"}`, string(edited.Raw()); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if err := edited.Unmarshal(&v); !errors.Is(err, lzjson.ErrorNonFinite) {
+		t.Errorf("expected ErrorNonFinite, got %#v", err)
+	}
+}
+
+func TestDecodeWithOptions_json5Error(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{"{a: 1,, }", "json: invalid character ',' at offset 6"},
+		{"{\n  multi-word: 1\n}", "json: invalid character '-' at offset 9"},
+		{"[1, 2] /* not closed", "json: unterminated comment at offset 7"},
+		{"'unterminated", "json: unexpected end of JSON5 input at offset 13"},
+		{"['é', 0123]", "json: invalid character '1' at offset 8"},
+		{`"\1"`, `json: invalid character '1' at offset 2`},
+		{"0x", "json: unexpected end of JSON5 input at offset 2"},
+	}
+	for _, test := range tests {
+		n := lzjson.DecodeWithOptions(strings.NewReader(test.input), lzjson.Options{Syntax: lzjson.SyntaxJSON5})
+		err := n.ParseError()
+		if err == nil {
+			t.Errorf("%#v: expected error %#v, got nil", test.input, test.err)
+			continue
+		}
+		if want, have := test.err, err.Error(); want != have {
+			t.Errorf("%#v: expected %#v, got %#v", test.input, want, have)
+		}
+		if !errors.Is(err, lzjson.ErrorSyntax) {
+			t.Errorf("%#v: expected ErrorSyntax, got %#v", test.input, err)
+		}
+	}
+}
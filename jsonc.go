@@ -0,0 +1,103 @@
+package lzjson
+
+// Syntax is the input syntax accepted by DecodeWithOptions
+type Syntax int
+
+// input syntaxes
+const (
+	// SyntaxJSON is the strict JSON of RFC 8259
+	SyntaxJSON Syntax = iota
+
+	// SyntaxJSONC is JSON with `//` and `/* */` comments
+	// and trailing commas in objects and arrays
+	SyntaxJSONC
+
+	// SyntaxJSON5 is JSON5 (https://spec.json5.org)
+	SyntaxJSON5
+)
+
+// normalize converts the input of the syntax
+// of the options into strict JSON
+func normalize(b []byte, opts Options) ([]byte, error) {
+	switch opts.Syntax {
+	case SyntaxJSONC:
+		return stripJSONC(b)
+	case SyntaxJSON5:
+		return fromJSON5(b)
+	}
+	return b, nil
+}
+
+// stripJSONC normalizes JSONC into strict JSON by blanking
+// comments and trailing commas with spaces. Line breaks in
+// comments are kept, so offsets and line numbers in any later
+// syntax error are the same as in the original input
+func stripJSONC(b []byte) ([]byte, error) {
+	out := make([]byte, len(b))
+	copy(out, b)
+
+	// blank the comments
+	for i := 0; i < len(out); i++ {
+		switch {
+		case out[i] == '"':
+			i = skipString(out, i)
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				if out[i] != '\r' {
+					out[i] = ' '
+				}
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			start := i
+			out[i], out[i+1] = ' ', ' '
+			for i += 2; ; i++ {
+				if i+1 >= len(out) {
+					return nil, syntaxError{"unterminated comment", start}
+				}
+				if out[i] == '*' && out[i+1] == '/' {
+					out[i], out[i+1] = ' ', ' '
+					i++
+					break
+				}
+				if out[i] != '\n' && out[i] != '\r' {
+					out[i] = ' '
+				}
+			}
+		}
+	}
+
+	// blank the trailing commas, which must follow a value
+	var prev byte
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '"':
+			i = skipString(out, i)
+		case ',':
+			afterValue := prev != 0 && prev != '[' && prev != '{' && prev != ',' && prev != ':'
+			if j := skipSpace(out, i+1); afterValue && j < len(out) && (out[j] == '}' || out[j] == ']') {
+				out[i] = ' '
+				continue
+			}
+		}
+		prev = c
+	}
+	return out, nil
+}
+
+// skipString returns the offset of the closing quote of
+// the string at b[i], or len(b) if it is not closed. The
+// content is not validated
+func skipString(b []byte, i int) int {
+	for i++; i < len(b); i++ {
+		switch b[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return i
+}
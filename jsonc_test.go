@@ -0,0 +1,98 @@
+package lzjson_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+func TestDecodeWithOptions_jsonc(t *testing.T) {
+	input := `// the config
+{
+  "url": "http://example.com/*not a comment*/", // a URL
+  /* block
+     comment */
+  "list": [1, 2, 3,],
+  "escaped": "quote \" // still a string",
+}
+`
+	n := lzjson.DecodeWithOptions(strings.NewReader(input), lzjson.Options{Syntax: lzjson.SyntaxJSONC})
+	if err := lzjson.Validate(n); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want, have := "http://example.com/*not a comment*/", n.Get("url").String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := `quote " // still a string`, n.Get("escaped").String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := 3, n.Get("list").Len(); want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+
+	var v struct {
+		List []int `json:"list"`
+	}
+	if err := n.Unmarshal(&v); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if want, have := 3, len(v.List); want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+
+	// raw is strict JSON of the same length as the input
+	if want, have := len(input), len(n.Raw()); want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+	if want, have := `{"url":"http://example.com/*not a comment*/","list":[1,2,3],"escaped":"quote \" // still a string"}`,
+		string(n.Compact().Raw()); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
+
+func TestDecodeWithOptions_jsoncError(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{"{\"a\": 1 /* comment */ x}", "json: invalid character 'x' at offset 22"},
+		{"// comment\n[1,, 2]", "json: invalid character ',' at offset 14"},
+		{"[1, 2] /* not closed", "json: unterminated comment at offset 7"},
+		{"{\"a\": 1,}", ""},
+		{"[1, 2,\n// comment\n]", ""},
+		{"[,]", "json: invalid character ',' at offset 1"},
+		{"{,}", "json: invalid character ',' at offset 1"},
+		{"[1,,]", "json: invalid character ',' at offset 3"},
+		{"{\"a\":,}", "json: invalid character ',' at offset 5"},
+		{"[\"a\",", "json: unexpected end of JSON input at offset 5"},
+	}
+	for _, test := range tests {
+		n := lzjson.DecodeWithOptions(strings.NewReader(test.input), lzjson.Options{Syntax: lzjson.SyntaxJSONC})
+		err := lzjson.Validate(n)
+		if test.err == "" {
+			if err != nil {
+				t.Errorf("%#v: unexpected error: %s", test.input, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%#v: expected error %#v, got nil", test.input, test.err)
+			continue
+		}
+		if want, have := test.err, err.Error(); want != have {
+			t.Errorf("%#v: expected %#v, got %#v", test.input, want, have)
+		}
+		if !errors.Is(err, lzjson.ErrorSyntax) {
+			t.Errorf("%#v: expected ErrorSyntax, got %#v", test.input, err)
+		}
+	}
+
+	// strict JSON does not accept comments or trailing commas
+	for _, input := range []string{`[1, 2,]`, `[1 /* one */]`} {
+		n := lzjson.DecodeWithOptions(strings.NewReader(input), lzjson.Options{})
+		if err := lzjson.Validate(n); !errors.Is(err, lzjson.ErrorSyntax) {
+			t.Errorf("%#v: expected ErrorSyntax, got %#v", input, err)
+		}
+	}
+}
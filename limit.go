@@ -0,0 +1,170 @@
+package lzjson
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// Options are the options of DecodeWithOptions. Zero
+// value of a limit means no limit
+type Options struct {
+	// Syntax is the syntax of the input. Input of other
+	// syntaxes is normalized into strict JSON on decode
+	Syntax Syntax
+
+	// NonFinite is the policy for Infinity, -Infinity
+	// and NaN in SyntaxJSON5 input, applied when the
+	// values are read by Raw, Unmarshal or MarshalJSON
+	NonFinite NonFinite
+
+	// MaxBytes is the maximum number of bytes to read
+	MaxBytes int64
+
+	// MaxDepth is the maximum nesting depth of objects and
+	// arrays. e.g. `{"a": [1]}` has a depth of 2
+	MaxDepth int
+
+	// MaxKeys is the maximum number of keys in an object
+	MaxKeys int
+
+	// MaxStringLen is the maximum number of bytes in a
+	// decoded string
+	MaxStringLen int
+
+	// MaxArrayLen is the maximum number of items in an array
+	MaxArrayLen int
+}
+
+// DecodeWithOptions reads and decodes a JSON from io.Reader with
+// limits, then returns a Node of it.
+//
+// Input of SyntaxJSONC is normalized into strict JSON, with comments
+// and trailing commas replaced by spaces. Raw and Unmarshal see the
+// strict JSON, while offsets in syntax errors match the original input.
+//
+// Input of SyntaxJSON5 is converted into compact strict JSON. As JSON
+// has no Infinity or NaN, they are converted as the NonFinite policy
+// tells when Raw, Unmarshal or MarshalJSON is called on a value that
+// contains them. Other values of the document can be read whatever
+// the policy. Syntax errors tell the offsets in the original input.
+//
+// MaxBytes is enforced on reading. Other limits are enforced lazily:
+// Get and GetN return a Node with ErrorLimitExceeded at the path of
+// the offending value. Unmarshal checks the whole value as Validate
+// does, Len returns -1 for arrays over MaxArrayLen and GetKeys returns
+// nil for objects over MaxKeys. Call Validate to enforce all limits
+// eagerly.
+func DecodeWithOptions(reader io.Reader, opts Options) Node {
+	if opts.MaxBytes > 0 {
+		reader = io.LimitReader(reader, opts.MaxBytes+1)
+	}
+	b, err := ioutil.ReadAll(reader)
+	if err == nil && opts.MaxBytes > 0 && int64(len(b)) > opts.MaxBytes {
+		return &rootNode{
+			err: Error{
+				Path: "json",
+				Err:  ErrorLimitExceeded,
+			},
+		}
+	}
+	if err == nil && opts.Syntax != SyntaxJSON {
+		if b, err = normalize(b, opts); err != nil {
+			if _, ok := err.(Error); !ok {
+				err = Error{Path: "json", Err: err}
+			}
+			return &rootNode{err: err}
+		}
+	}
+	n := &rootNode{
+		buf:    b,
+		obj:    &objectCache{},
+		err:    err,
+		limits: &opts,
+	}
+	if err != nil {
+		return n
+	}
+	return n.limit()
+}
+
+// limit checks the node's value against the limits that do not
+// require scanning into the value. Returns a Node with
+// ErrorLimitExceeded if exceeded, or the node itself if not
+func (n *rootNode) limit() *rootNode {
+	if n.limits == nil {
+		return n
+	}
+	if b := bytes.TrimSpace(n.buf); len(b) > 0 && !n.limits.allow(b, n.depth) {
+		return &rootNode{
+			path: n.path,
+			err: Error{
+				Path: "json" + n.path,
+				Err:  ErrorLimitExceeded,
+			},
+		}
+	}
+	return n
+}
+
+// allow checks the depth and string length limits of the
+// value, which is contained by the given number of objects
+// and arrays
+func (opts *Options) allow(b []byte, depth int) bool {
+	switch {
+	case (b[0] == '{' || b[0] == '[') && opts.MaxDepth > 0:
+		return depth+1 <= opts.MaxDepth
+	case b[0] == '"' && opts.Syntax == SyntaxJSON5 && bytes.HasPrefix(b, []byte(nonFinitePrefix)):
+		return true // a non-finite number
+	case b[0] == '"' && opts.MaxStringLen > 0 && len(b)-2 > opts.MaxStringLen:
+		// the raw string may be longer than decoded due to escapes
+		return len(unquote(b)) <= opts.MaxStringLen
+	}
+	return true
+}
+
+// validate checks the validated raw JSON value
+// at the path against all the limits
+func (opts *Options) validate(b []byte, path string, depth int) error {
+	exceeded := Error{Path: "json" + path, Err: ErrorLimitExceeded}
+	if !opts.allow(b, depth) {
+		return exceeded
+	}
+	switch b[0] {
+	case '{':
+		members, _, _ := scanObject(b, 0)
+		if opts.MaxKeys > 0 && len(members) > opts.MaxKeys {
+			return exceeded
+		}
+		for _, m := range members {
+			if err := opts.validate(b[m.valStart:m.valEnd], keyPath(path, m.key), depth+1); err != nil {
+				return err
+			}
+		}
+	case '[':
+		elems, _, _ := scanArray(b, 0)
+		if opts.MaxArrayLen > 0 && len(elems) > opts.MaxArrayLen {
+			return exceeded
+		}
+		for i, e := range elems {
+			if err := opts.validate(b[e.start:e.end], nthPath(path, i), depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the whole node eagerly. It returns the
+// parse error of the node, or an Error if the node is undefined,
+// is not valid JSON, or exceeds any limit of DecodeWithOptions
+func Validate(n Node) error {
+	b, err := definedValue(n)
+	if err != nil {
+		return err
+	}
+	if rn, ok := n.(*rootNode); ok && rn.limits != nil {
+		return rn.limits.validate(b, rn.path, rn.depth)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package lzjson_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+func TestDecodeWithOptions(t *testing.T) {
+	opts := lzjson.Options{
+		MaxBytes:     1024,
+		MaxDepth:     3,
+		MaxKeys:      4,
+		MaxStringLen: 5,
+		MaxArrayLen:  3,
+	}
+	n := lzjson.DecodeWithOptions(strings.NewReader(`{
+		"bio": "long long ago",
+		"escaped": "\u0041BCDE",
+		"tags": ["a", "b", "c", "d"],
+		"nested": {"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}
+	}`), opts)
+
+	if want, have := "ABCDE", n.Get("escaped").String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+
+	tests := []struct {
+		Node lzjson.Node
+		Err  string
+	}{
+		{n.Get("bio"), "json.bio: limit exceeded"},
+		{n.Get("nested").Get("a"), "json.nested.a: limit exceeded (json.nested exceeds the limits)"},
+		{n.Get("tags").GetN(0), "json.tags[0]: limit exceeded (json.tags exceeds the limits)"},
+	}
+	for _, test := range tests {
+		err := test.Node.ParseError()
+		if err == nil {
+			t.Errorf("expected error %#v, got nil", test.Err)
+			continue
+		}
+		if want, have := test.Err, err.Error(); want != have {
+			t.Errorf("expected %#v, got %#v", want, have)
+		}
+		if want, have := lzjson.ErrorLimitExceeded, err.(lzjson.Error).Err; want != have {
+			t.Errorf("expected %#v, got %#v", want, have)
+		}
+	}
+}
+
+func TestDecodeWithOptions_lazy(t *testing.T) {
+	n := lzjson.DecodeWithOptions(strings.NewReader(
+		`{"a": {"b": {"c": {}}, "ok": "short"}, "long": "long long ago", "arr": [[1, 2, 3]]}`,
+	), lzjson.Options{MaxDepth: 3, MaxStringLen: 5, MaxArrayLen: 2})
+
+	// values within the limits are accessible
+	if want, have := "short", n.Get("a").Get("ok").String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := lzjson.TypeObject, n.Get("a").Get("b").Type(); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+	if want, have := lzjson.TypeArray, n.Get("arr").GetN(0).Type(); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+
+	// values exceeding the limits are not
+	tests := []struct {
+		Node lzjson.Node
+		Err  string
+	}{
+		{n.Get("a").Get("b").Get("c"), "json.a.b.c: limit exceeded"},
+		{n.Get("long"), "json.long: limit exceeded"},
+		{n.Get("arr").GetN(0).GetN(0), "json.arr[0][0]: limit exceeded (json.arr[0] exceeds the limits)"},
+	}
+	for _, test := range tests {
+		if err := test.Node.ParseError(); err == nil {
+			t.Errorf("expected error %#v, got nil", test.Err)
+		} else if want, have := test.Err, err.Error(); want != have {
+			t.Errorf("expected %#v, got %#v", want, have)
+		}
+	}
+}
+
+func TestDecodeWithOptions_eager(t *testing.T) {
+	var v interface{}
+	n := lzjson.DecodeWithOptions(strings.NewReader(`{"a": [[[[[[1]]]]]]}`), lzjson.Options{MaxDepth: 2})
+	if err := n.Unmarshal(&v); err == nil {
+		t.Error("expected error, got nil")
+	} else if want, have := "json.a[0]: limit exceeded", err.Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := lzjson.TypeArray, n.Get("a").Type(); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+
+	n = lzjson.DecodeWithOptions(strings.NewReader(`{"a": [1, 2, 3], "b": [1, 2, 3, 4]}`), lzjson.Options{MaxArrayLen: 3})
+	if err := n.Get("a").Unmarshal(&v); err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+	if want, have := 3, n.Get("a").Len(); want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+	if want, have := -1, n.Get("b").Len(); want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+	if err := n.Get("b").Unmarshal(&v); err == nil {
+		t.Error("expected error, got nil")
+	} else if want, have := "json.b: limit exceeded", err.Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+
+	n = lzjson.DecodeWithOptions(strings.NewReader(`{"a": 1, "b": 2, "c": 3}`), lzjson.Options{MaxKeys: 2})
+	if have := n.GetKeys(); have != nil {
+		t.Errorf("expected nil, got %#v", have)
+	}
+}
+
+func TestDecodeWithOptions_maxBytes(t *testing.T) {
+	n := lzjson.DecodeWithOptions(strings.NewReader(`{"a": 1}`), lzjson.Options{MaxBytes: 8})
+	if err := n.ParseError(); err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+
+	n = lzjson.DecodeWithOptions(strings.NewReader(`{"a": 12}`), lzjson.Options{MaxBytes: 8})
+	if want, have := lzjson.TypeError, n.Type(); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+	if err := n.ParseError(); err == nil {
+		t.Errorf("expected error, got nil")
+	} else if want, have := "json: limit exceeded", err.Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := 0, len(n.Raw()); want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	opts := lzjson.Options{MaxDepth: 2, MaxKeys: 2, MaxStringLen: 3, MaxArrayLen: 2}
+	tests := []struct {
+		JSON string
+		Err  string
+	}{
+		{`{"a": [1, "abc"], "b": {}}`, ""},
+		{`"abc"`, ""},
+		{`"abcd"`, "json: limit exceeded"},
+		{`{"a": 1, "b": 2, "c": 3}`, "json: limit exceeded"},
+		{`{"a": [1, 2, 3]}`, "json.a: limit exceeded"},
+		{`{"a": [[]]}`, "json.a[0]: limit exceeded"},
+		{`{"a": {"b c": "abcd"}}`, `json.a["b c"]: limit exceeded`},
+		{`{"a": [1, 2,]}`, "json: invalid character ']' at offset 12"},
+		{``, "json: undefined"},
+	}
+	for _, test := range tests {
+		err := lzjson.Validate(lzjson.DecodeWithOptions(strings.NewReader(test.JSON), opts))
+		if test.Err == "" {
+			if err != nil {
+				t.Errorf("json=%s unexpected error: %s", test.JSON, err.Error())
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("json=%s expected error %#v, got nil", test.JSON, test.Err)
+		} else if want, have := test.Err, err.Error(); want != have {
+			t.Errorf("json=%s expected %#v, got %#v", test.JSON, want, have)
+		}
+	}
+
+	// validate an inner node with the limits of its root
+	n := lzjson.DecodeWithOptions(strings.NewReader(`{"a": {"b": [1, 2, 3]}}`), lzjson.Options{MaxArrayLen: 2})
+	if err := lzjson.Validate(n.Get("a")); err == nil {
+		t.Errorf("expected error, got nil")
+	} else if want, have := "json.a.b: limit exceeded", err.Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+
+	// nodes without limits are only checked for syntax
+	if err := lzjson.Validate(lzjson.ParseString(`{"a": [1, 2, 3]}`)); err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+}
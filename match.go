@@ -0,0 +1,185 @@
+package lzjson
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// nodePath returns the path of the node, if known
+func nodePath(n Node) string {
+	if rn, ok := n.(*rootNode); ok {
+		return rn.path
+	}
+	return ""
+}
+
+// typeName returns the name of the validated raw JSON value's
+// type as used in messages and the $type placeholder
+func typeName(b []byte) string {
+	switch {
+	case b[0] == '{':
+		return "object"
+	case b[0] == '[':
+		return "array"
+	case b[0] == '"':
+		return "string"
+	case isNumStart(b[0]):
+		return "number"
+	case b[0] == 'n':
+		return "null"
+	}
+	return "bool"
+}
+
+// isInteger tells if the validated raw JSON number is an integer
+func isInteger(b []byte) bool {
+	n := normNumber(b)
+	i := strings.LastIndexByte(n, 'e')
+	return i < 0 || n[i+1] != '-'
+}
+
+// Match checks if the actual node contains at least what is
+// described by the expected node. It returns nil if matched,
+// or Errors with every mismatch found.
+//
+// Objects in expected may omit members of actual, while arrays
+// must have the same length and match item by item. Other values
+// must be equal as in Equal. Instead of a value, expected may
+// use a placeholder object:
+//
+//	{"$type": "string"}  matches any value of the type (string, number,
+//	                     integer, object, array, bool or null;
+//	                     boolean is accepted as in JSON Schema)
+//	{"$regex": "^usr_"}  matches any string matching the regular expression
+//
+// Both placeholder keys may be used together in the same object.
+func Match(actual, expected Node) error {
+	e, err := rawValue(expected)
+	if err != nil {
+		if lerr, ok := err.(Error); ok {
+			return Errors{lerr}
+		}
+		return Errors{{Path: "json" + nodePath(expected), Err: err}}
+	}
+	path := nodePath(actual)
+	a, err := rawValue(actual)
+	if err != nil {
+		if lerr, ok := err.(Error); ok {
+			return Errors{lerr}
+		}
+		return Errors{{Path: "json" + path, Err: err}}
+	}
+	if len(e) == 0 {
+		return nil
+	}
+	if len(a) == 0 {
+		return Errors{{Path: "json" + path, Err: ErrorUndefined}}
+	}
+
+	errs := Errors{}
+	match(a, e, path, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// placeholder returns the $type and $regex of a placeholder
+// object, or ok = false if the object is not a placeholder
+func placeholder(e []byte) (typ, re string, ok bool) {
+	members, _, _ := scanObject(e, 0)
+	if len(members) == 0 {
+		return
+	}
+	for _, m := range members {
+		v := e[m.valStart:m.valEnd]
+		switch {
+		case m.key == "$type" && v[0] == '"':
+			typ = unquote(v)
+		case m.key == "$regex" && v[0] == '"':
+			re = unquote(v)
+		default:
+			return "", "", false
+		}
+	}
+	return typ, re, true
+}
+
+// match appends mismatches of the validated raw JSON values
+func match(a, e []byte, path string, errs *Errors) {
+	mismatch := func(format string, args ...interface{}) {
+		*errs = append(*errs, Error{Path: "json" + path, Err: fmt.Errorf(format, args...)})
+	}
+
+	if e[0] == '{' {
+		if typ, re, ok := placeholder(e); ok {
+			matchPlaceholder(a, typ, re, mismatch)
+			return
+		}
+	}
+
+	switch {
+	case typeName(a) != typeName(e):
+		*errs = append(*errs, Error{Path: "json" + path, Err: mismatchError{typeName(e), typeName(a)}})
+	case e[0] == '{':
+		am, _, _ := scanObject(a, 0)
+		em, _, _ := scanObject(e, 0)
+		for i, m := range em {
+			if lastMember(em, m.key) != i {
+				continue // overridden by duplicated key
+			}
+			j := lastMember(am, m.key)
+			if j < 0 {
+				*errs = append(*errs, Error{Path: "json" + keyPath(path, m.key), Err: ErrorUndefined})
+				continue
+			}
+			match(a[am[j].valStart:am[j].valEnd], e[m.valStart:m.valEnd], keyPath(path, m.key), errs)
+		}
+	case e[0] == '[':
+		ae, _, _ := scanArray(a, 0)
+		ee, _, _ := scanArray(e, 0)
+		if len(ae) != len(ee) {
+			mismatch("expected %d items, got %d", len(ee), len(ae))
+			return
+		}
+		for i := range ee {
+			match(a[ae[i].start:ae[i].end], e[ee[i].start:ee[i].end], nthPath(path, i), errs)
+		}
+	case !jsonEqual(a, e):
+		mismatch("expected %s, got %s", e, a)
+	}
+}
+
+// matchPlaceholder checks the validated raw JSON value
+// against a placeholder
+func matchPlaceholder(a []byte, typ, re string, mismatch func(string, ...interface{})) {
+	if typ == "boolean" {
+		typ = "bool" // as in JSON Schema
+	}
+	switch typ {
+	case "", typeName(a):
+	case "integer":
+		if typeName(a) != "number" || !isInteger(a) {
+			mismatch("expected integer, got %s", a)
+			return
+		}
+	case "string", "number", "object", "array", "bool", "null":
+		mismatch("expected %s, got %s", typ, typeName(a))
+		return
+	default:
+		mismatch("invalid placeholder: unknown $type %#v", typ)
+		return
+	}
+	if re == "" {
+		return
+	}
+	r, err := regexp.Compile(re)
+	if err != nil {
+		mismatch("invalid placeholder: %s", err.Error())
+	} else if a[0] != '"' {
+		mismatch("expected string matching /%s/, got %s", re, typeName(a))
+	} else if str := unquote(a); !r.MatchString(str) {
+		mismatch("expected string matching /%s/, got %#v", re, str)
+	}
+}
@@ -0,0 +1,115 @@
+package lzjson_test
+
+import (
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+func TestMatch(t *testing.T) {
+	actual := lzjson.ParseString(`{
+		"id": "usr_1234",
+		"name": "John",
+		"age": 42,
+		"score": 4.5,
+		"tags": ["a", "b"],
+		"address": {"city": "Hong Kong", "zip": null},
+		"created": "2016-12-06T00:00:00Z",
+		"active": true
+	}`)
+
+	matched := []string{
+		`{}`,
+		`{"name": "John"}`,
+		`{"age": 42.0, "tags": ["a", "b"]}`,
+		`{"address": {"city": "Hong Kong"}}`,
+		`{"id": {"$regex": "^usr_"}, "created": {"$type": "string"}}`,
+		`{"age": {"$type": "integer"}, "score": {"$type": "number"}}`,
+		`{"address": {"zip": {"$type": "null"}}, "tags": {"$type": "array"}}`,
+		`{"tags": [{"$type": "string", "$regex": "^[a-z]$"}, "b"]}`,
+		`{"active": {"$type": "bool"}}`,
+		`{"active": {"$type": "boolean"}}`,
+	}
+	for _, str := range matched {
+		if err := lzjson.Match(actual, lzjson.ParseString(str)); err != nil {
+			t.Errorf("expected=%s unexpected error: %s", str, err.Error())
+		}
+	}
+}
+
+func TestMatch_error(t *testing.T) {
+	actual := lzjson.ParseString(`{
+		"id": "usr_1234",
+		"age": 42.5,
+		"tags": ["a", "b"],
+		"address": {"city": "Hong Kong"}
+	}`)
+
+	tests := []struct {
+		Expected string
+		Errs     []string
+	}{
+		{`{"name": "John"}`, []string{"json.name: undefined"}},
+		{
+			`{"id": {"$regex": "^acc_"}, "age": {"$type": "integer"}, "address": {"city": "London", "zip": "000"}}`,
+			[]string{
+				`json.id: expected string matching /^acc_/, got "usr_1234"`,
+				`json.age: expected integer, got 42.5`,
+				`json.address.city: expected "London", got "Hong Kong"`,
+				`json.address.zip: undefined`,
+			},
+		},
+		{`{"tags": ["a"]}`, []string{"json.tags: expected 1 items, got 2"}},
+		{`{"tags": ["a", 2]}`, []string{"json.tags[1]: expected number, got string"}},
+		{`{"tags": {"$type": "object"}}`, []string{"json.tags: expected object, got array"}},
+		{`{"id": {"$type": "boolean"}}`, []string{"json.id: expected bool, got string"}},
+		{`{"age": {"$regex": "^4"}}`, []string{"json.age: expected string matching /^4/, got number"}},
+		{`{"age": {"$type": "decimal"}}`, []string{`json.age: invalid placeholder: unknown $type "decimal"`}},
+		{`[]`, []string{"json: expected array, got object"}},
+	}
+	for _, test := range tests {
+		err := lzjson.Match(actual, lzjson.ParseString(test.Expected))
+		if err == nil {
+			t.Errorf("expected=%s expected error, got nil", test.Expected)
+			continue
+		}
+		errs, ok := err.(lzjson.Errors)
+		if !ok {
+			t.Errorf("expected=%s expected lzjson.Errors, got %#v", test.Expected, err)
+			continue
+		}
+		if want, have := len(test.Errs), len(errs); want != have {
+			t.Errorf("expected=%s expected %d errors, got %d:\n%s", test.Expected, want, have, err.Error())
+			continue
+		}
+		for i := range errs {
+			if want, have := test.Errs[i], errs[i].Error(); want != have {
+				t.Errorf("expected=%s errs[%d] expected %#v, got %#v", test.Expected, i, want, have)
+			}
+		}
+	}
+}
+
+func TestMatch_path(t *testing.T) {
+	root := lzjson.ParseString(`{"data": {"items": [{"id": 1}]}}`)
+	err := lzjson.Match(root.Get("data").Get("items").GetN(0), lzjson.ParseString(`{"id": 2}`))
+	if err == nil {
+		t.Error("expected error, got nil")
+	} else if want, have := "json.data.items[0].id: expected 2, got 1", err.Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+
+	err = lzjson.Match(root.Get("meta"), lzjson.ParseString(`{"id": 2}`))
+	if err == nil {
+		t.Error("expected error, got nil")
+	} else if want, have := "json.meta: undefined", err.Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+
+	err = lzjson.Match(root, lzjson.ParseString(`{"id": 2,}`))
+	if err == nil {
+		t.Error("expected error, got nil")
+	} else if want, have := "json: invalid character '}' at offset 9", err.Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
@@ -0,0 +1,161 @@
+package lzjson
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// rawValue returns the raw JSON value of the node with
+// surrounding whitespaces trimmed. It returns error if the
+// node has parse error or the raw JSON is malformed
+func rawValue(n Node) ([]byte, error) {
+	if err := n.ParseError(); err != nil {
+		return nil, err
+	}
+	var raw []byte
+	if rn, ok := n.(*rootNode); ok {
+		var err error
+		if raw, err = rn.resolvedRaw(); err != nil {
+			return nil, err
+		}
+	} else {
+		raw = n.Raw()
+	}
+	start := skipSpace(raw, 0)
+	if start == len(raw) {
+		return raw[start:], nil
+	}
+
+	// scan the untrimmed raw, so offsets in errors are of the input
+	end, err := scanValue(raw, start)
+	if err != nil {
+		return nil, err
+	} else if i := skipSpace(raw, end); i != len(raw) {
+		return nil, unexpected(raw, i)
+	}
+	return raw[start:end], nil
+}
+
+// definedValue is like rawValue, but an undefined node or
+// invalid JSON value is reported as Error with the node's path
+func definedValue(n Node) ([]byte, error) {
+	b, err := rawValue(n)
+	if err != nil {
+		if _, ok := err.(Error); !ok && n.ParseError() == nil {
+			err = Error{Path: "json" + nodePath(n), Err: err}
+		}
+		return nil, err
+	} else if len(b) == 0 {
+		return nil, Error{Path: "json" + nodePath(n), Err: ErrorUndefined}
+	}
+	return b, nil
+}
+
+// MergePatch applies a JSON Merge Patch (RFC 7396) to the target
+// and returns the result as a new Node. The target is not modified,
+// and the parts of it not touched by the patch stay byte-identical.
+//
+// An undefined target is treated as an absent value.
+func MergePatch(target, patch Node) (Node, error) {
+	t, err := rawValue(target)
+	if err != nil {
+		return nil, err
+	}
+	p, err := rawValue(patch)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(mergePatch(t, p)), nil
+}
+
+// mergePatch implements the MergePatch algorithm of RFC 7396
+// on validated raw JSON values. A nil or empty target is absent
+func mergePatch(target, patch []byte) []byte {
+	if len(patch) == 0 || patch[0] != '{' {
+		return patch
+	}
+	if len(target) == 0 || target[0] != '{' {
+		target = []byte("{}")
+	}
+	members, _, _ := scanObject(patch, 0)
+	for _, m := range members {
+		v := patch[m.valStart:m.valEnd]
+		if string(v) == "null" {
+			target = deleteMember(target, 0, m.key)
+			continue
+		}
+		var orig []byte
+		tm, _, _ := scanObject(target, 0)
+		if i := lastMember(tm, m.key); i >= 0 {
+			orig = target[tm[i].valStart:tm[i].valEnd]
+		}
+		target = setMember(target, 0, m.key, mergePatch(orig, v))
+	}
+	return target
+}
+
+// CreateMergePatch returns a JSON Merge Patch (RFC 7396) which
+// turns the original into the modified value when applied with
+// MergePatch.
+//
+// Note that merge patch cannot set a member to null, as null
+// means removal. Such changes are expressed as removal instead.
+func CreateMergePatch(original, modified Node) (Node, error) {
+	o, err := rawValue(original)
+	if err != nil {
+		return nil, err
+	}
+	m, err := rawValue(modified)
+	if err != nil {
+		return nil, err
+	}
+	if len(o) == 0 || o[0] != '{' || len(m) == 0 || m[0] != '{' {
+		return Parse(m), nil
+	}
+	return Parse(createMergePatch(o, m)), nil
+}
+
+// createMergePatch returns the merge patch between two
+// validated raw JSON objects
+func createMergePatch(original, modified []byte) []byte {
+	patch := []byte("{}")
+	om, _, _ := scanObject(original, 0)
+	mm, _, _ := scanObject(modified, 0)
+
+	for i, m := range mm {
+		if lastMember(mm, m.key) != i {
+			continue // overridden by duplicated key
+		}
+		mv := modified[m.valStart:m.valEnd]
+		j := lastMember(om, m.key)
+		if j < 0 {
+			patch = setMember(patch, 0, m.key, compactRaw(mv))
+			continue
+		}
+		ov := original[om[j].valStart:om[j].valEnd]
+		switch {
+		case ov[0] == '{' && mv[0] == '{':
+			if sub := createMergePatch(ov, mv); string(sub) != "{}" {
+				patch = setMember(patch, 0, m.key, sub)
+			}
+		case !bytes.Equal(compactRaw(ov), compactRaw(mv)):
+			patch = setMember(patch, 0, m.key, compactRaw(mv))
+		}
+	}
+	for _, m := range om {
+		if lastMember(mm, m.key) < 0 && !hasMember(patch, 0, m.key) {
+			patch = setMember(patch, 0, m.key, []byte("null"))
+		}
+	}
+	return patch
+}
+
+// compactRaw returns the validated raw JSON with
+// insignificant whitespaces removed
+func compactRaw(b []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, b); err != nil {
+		return b
+	}
+	return buf.Bytes()
+}
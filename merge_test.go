@@ -0,0 +1,155 @@
+package lzjson_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+// jsonEqual tells if the 2 JSON strings are semantically equal
+func jsonEqual(a, b string) bool {
+	var va, vb interface{}
+	if err := json.Unmarshal([]byte(a), &va); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(b), &vb); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// mergePatchCases are the test cases in RFC 7396 Appendix A
+var mergePatchCases = []struct {
+	Target string
+	Patch  string
+	Result string
+}{
+	{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+	{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+	{`{"a":"b"}`, `{"a":null}`, `{}`},
+	{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+	{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+	{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+	{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+	{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+	{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+	{`{"a":"b"}`, `["c"]`, `["c"]`},
+	{`{"a":"foo"}`, `null`, `null`},
+	{`{"a":"foo"}`, `"bar"`, `"bar"`},
+	{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+	{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+	{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+}
+
+func TestMergePatch(t *testing.T) {
+	for _, test := range mergePatchCases {
+		n, err := lzjson.MergePatch(lzjson.ParseString(test.Target), lzjson.ParseString(test.Patch))
+		if err != nil {
+			t.Errorf("target=%s patch=%s unexpected error: %s", test.Target, test.Patch, err.Error())
+			continue
+		}
+		if want, have := test.Result, string(n.Raw()); want != have {
+			t.Errorf("target=%s patch=%s expected %s, got %s", test.Target, test.Patch, want, have)
+		}
+	}
+}
+
+func TestMergePatch_formatting(t *testing.T) {
+	target := lzjson.ParseString(`{
+  "title": "Goodbye!",
+  "author" : {
+    "givenName" : "John",
+    "familyName" : "Doe"
+  },
+  "tags":[ "example", "sample" ],
+  "content": "This will be unchanged"
+}`)
+	patch := lzjson.ParseString(`{
+  "title": "Hello!",
+  "phoneNumber": "+01-123-456-7890",
+  "author": {
+    "familyName": null
+  },
+  "tags": [ "example" ]
+}`)
+	n, err := lzjson.MergePatch(target, patch)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+		return
+	}
+	expected := `{
+  "title": "Hello!",
+  "author" : {
+    "givenName" : "John"
+  },
+  "tags":[ "example" ],
+  "content": "This will be unchanged",
+  "phoneNumber": "+01-123-456-7890"
+}`
+	if want, have := expected, string(n.Raw()); want != have {
+		t.Errorf("\nexpected:\n%s\ngot:\n%s", want, have)
+	}
+}
+
+func TestMergePatch_error(t *testing.T) {
+	root := lzjson.ParseString(`{"a": 1}`)
+	if _, err := lzjson.MergePatch(root.Get("b"), root); err == nil {
+		t.Error("expected error, got nil")
+	} else if want, have := "json.b: undefined", err.Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if _, err := lzjson.MergePatch(root, lzjson.ParseString(`{"a": }`)); err == nil {
+		t.Error("expected error, got nil")
+	}
+	if n, err := lzjson.MergePatch(lzjson.NewNode(), lzjson.ParseString(`{"a": {"b": null}}`)); err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+	} else if want, have := `{"a":{}}`, string(n.Raw()); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	tests := []struct {
+		Original string
+		Modified string
+		Patch    string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"a":"b","b":"c"}`, `{"b":"c"}`},
+		{`{"a":"b","b":"c"}`, `{"b":"c"}`, `{"a":null}`},
+		{`{"a":{"b":"c","d":1}}`, `{"a":{"b":"d","d":1}}`, `{"a":{"b":"d"}}`},
+		{`{"a": [1, 2]}`, `{"a":[1,2]}`, `{}`},
+		{`{"a":[1,2]}`, `{"a":[2,1]}`, `{"a":[2,1]}`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`[1,2]`, `{"a":"b"}`, `{"a":"b"}`},
+	}
+	for _, test := range tests {
+		patch, err := lzjson.CreateMergePatch(lzjson.ParseString(test.Original), lzjson.ParseString(test.Modified))
+		if err != nil {
+			t.Errorf("original=%s modified=%s unexpected error: %s", test.Original, test.Modified, err.Error())
+			continue
+		}
+		if want, have := test.Patch, string(patch.Raw()); want != have {
+			t.Errorf("original=%s modified=%s expected %s, got %s", test.Original, test.Modified, want, have)
+		}
+	}
+
+	// patch created from the RFC cases should reproduce the result
+	for _, test := range mergePatchCases {
+		patch, err := lzjson.CreateMergePatch(lzjson.ParseString(test.Target), lzjson.ParseString(test.Result))
+		if err != nil {
+			t.Errorf("target=%s result=%s unexpected error: %s", test.Target, test.Result, err.Error())
+			continue
+		}
+		n, err := lzjson.MergePatch(lzjson.ParseString(test.Target), patch)
+		if err != nil {
+			t.Errorf("target=%s patch=%s unexpected error: %s", test.Target, patch.Raw(), err.Error())
+			continue
+		}
+		if !jsonEqual(test.Result, string(n.Raw())) {
+			t.Errorf("target=%s patch=%s expected %s, got %s", test.Target, patch.Raw(), test.Result, n.Raw())
+		}
+	}
+}
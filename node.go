@@ -1,12 +1,14 @@
 package lzjson
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"regexp"
 	"strings"
+	"sync"
 )
 
 // reNumber is the regular expression to match
@@ -19,7 +21,12 @@ func isNumJSON(b []byte) bool {
 	return reNum.Match(b)
 }
 
-// Node is an interface for all JSON nodes
+// Node is an interface for all JSON nodes.
+//
+// Nodes are immutable except by UnmarshalJSON. It is safe to
+// share a Node across goroutines and call its other methods
+// concurrently: parsed values are cached lazily in a way safe
+// for concurrent readers.
 type Node interface {
 
 	// Unmarshal parses the JSON node data into variable v
@@ -28,14 +35,17 @@ type Node interface {
 	// UnmarshalJSON implements json.Unmarshaler
 	UnmarshalJSON(b []byte) error
 
+	// MarshalJSON implements json.Marshaler
+	MarshalJSON() ([]byte, error)
+
 	// Raw returns the raw JSON string in []byte
 	Raw() []byte
 
 	// Type returns the Type of the containing JSON value
 	Type() Type
 
-	// GetKeys gets an array object's keys,
-	// or nil if not an object
+	// GetKeys gets an array object's keys, or nil if
+	// not an object or if it exceeds the MaxKeys limit
 	GetKeys() []string
 
 	// Get gets object's inner value.
@@ -43,7 +53,8 @@ type Node interface {
 	Get(key string) (inner Node)
 
 	// Len gets the length of the value
-	// Only works with Array and String value type
+	// Only works with Array and String value type.
+	// Returns -1 for an array over the MaxArrayLen limit
 	Len() int
 
 	// GetN gets array's inner value.
@@ -68,6 +79,42 @@ type Node interface {
 
 	// ParseError returns the JSON parse error, if any
 	ParseError() error
+
+	// Set returns a new Node with the value at the selector
+	// (e.g. `foo.bar[2]["hello world"]`) replaced by the given
+	// value. Setting a non-existing key adds it to the object.
+	// The original Node is not modified.
+	Set(sel string, value interface{}) (Node, error)
+
+	// Delete returns a new Node with the value at the
+	// selector removed from its parent object or array.
+	// The original Node is not modified.
+	Delete(sel string) (Node, error)
+
+	// Insert returns a new Node with the value inserted into
+	// the array at the selector, before its nth item.
+	// The original Node is not modified.
+	Insert(sel string, nth int, value interface{}) (Node, error)
+
+	// Indent returns a new Node of the value indented as
+	// json.Indent does. Key order, string escapes and number
+	// formats are kept. Like other methods returning Node,
+	// a node with parse error or invalid JSON value results
+	// in a Node with ParseError.
+	Indent(prefix, indent string) Node
+
+	// Compact returns a new Node of the value with all
+	// insignificant whitespaces removed.
+	Compact() Node
+
+	// Canonical returns a new Node of the value in the form of
+	// JSON Canonicalization Scheme (RFC 8785), suitable for
+	// hashing or signing. Object keys are sorted, numbers and
+	// strings are serialized as ECMAScript JSON.stringify does.
+	// Numbers out of IEEE 754 double range and strings that are
+	// not well-formed Unicode (e.g. a lone "\ud800") are reported
+	// by ParseError of the returned Node.
+	Canonical() Node
 }
 
 // NewNode returns an initialized empty Node value
@@ -82,64 +129,179 @@ func Decode(reader io.Reader) Node {
 	b, err := ioutil.ReadAll(reader)
 	return &rootNode{
 		buf: b,
+		obj: &objectCache{},
 		err: err,
 	}
 }
 
+// Parse returns a Node of the given JSON bytes.
+// The bytes are used as-is without copying, so the
+// caller should not modify them afterwards
+func Parse(b []byte) Node {
+	return &rootNode{
+		buf: b,
+		obj: &objectCache{},
+	}
+}
+
+// ParseString returns a Node of the given JSON string
+func ParseString(str string) Node {
+	return Parse([]byte(str))
+}
+
+// FromValue marshals the given value with json.Marshal
+// then returns a Node of the result
+func FromValue(v interface{}) (Node, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b), nil
+}
+
+// Select gets the inner value at the selector
+// (e.g. `foo.bar[2]["hello world"]`) with Get and GetN.
+// Only an invalid selector is returned as error. Failed
+// lookup is reported by ParseError of the returned Node,
+// just like Get and GetN
+func Select(n Node, sel string) (Node, error) {
+	steps, err := parseSel(sel)
+	if err != nil {
+		return nil, err
+	}
+	for _, step := range steps {
+		if step.isIndex {
+			n = n.GetN(step.nth)
+		} else {
+			n = n.Get(step.key)
+		}
+	}
+	return n, nil
+}
+
+// objectCache holds the lazily parsed members of an object
+// node. It is shared by the copies of the node and is safe
+// for concurrent use
+type objectCache struct {
+	once    sync.Once
+	members map[string]rootNode
+	err     error
+}
+
 // rootNode is the default implementation of Node
 type rootNode struct {
 	path   string
 	buf    []byte
-	mapBuf map[string]rootNode
+	obj    *objectCache // nil to parse the members on every use
 	err    error
+	limits *Options // nil for no limits
+	depth  int      // number of objects and arrays containing the node
 }
 
 // Unmarshal implements Node
+//
+// For a node of DecodeWithOptions, the whole value is checked
+// against the limits first, and an Error of ErrorLimitExceeded
+// is returned if any is exceeded
 func (n *rootNode) Unmarshal(v interface{}) error {
-	return json.Unmarshal(n.buf, v)
+	b, err := n.resolvedRaw()
+	if err != nil {
+		return err
+	}
+	if n.limits != nil {
+		if rb, err := rawValue(n); err == nil && len(rb) > 0 {
+			if err := n.limits.validate(rb, n.path, n.depth); err != nil {
+				return err
+			}
+		}
+	}
+	return json.Unmarshal(b, v)
 }
 
 // UnmarshalJSON implements Node
 func (n *rootNode) UnmarshalJSON(b []byte) error {
-	n.buf = b
-	n.mapBuf = nil
+	// json.Unmarshaler must copy the data if it
+	// wishes to retain it after returning
+	n.buf = append([]byte(nil), b...)
+	n.obj = &objectCache{}
+	n.err = nil
 	return nil
 }
 
+// MarshalJSON implements Node
+//
+// The raw bytes of the node are returned as-is. An undefined
+// node is marshaled as null, and a node with parse error
+// returns the error so it would not be silently encoded.
+func (n *rootNode) MarshalJSON() ([]byte, error) {
+	switch n.Type() {
+	case TypeUndefined:
+		return []byte("null"), nil
+	case TypeError:
+		if err := n.ParseError(); err != nil {
+			return nil, err
+		}
+		return nil, Error{
+			Path: "json" + n.path,
+			Err:  fmt.Errorf("invalid JSON value %q", n.buf),
+		}
+	}
+	return n.resolvedRaw()
+}
+
 // Raw implements Node
 func (n *rootNode) Raw() []byte {
-	return n.buf
+	b, err := n.resolvedRaw()
+	if err != nil {
+		// refused by NonFiniteError, as written in the input
+		b, _ = replaceNonFinite(n.buf, n.path, func(text string, _ int, _ string) ([]byte, error) {
+			return []byte(text), nil
+		})
+	}
+	return b
 }
 
 // Type implements Node
 func (n rootNode) Type() Type {
 
+	// skip the whitespace (or blanked comments) around the value
+	b := bytes.TrimSpace(n.buf)
+
 	switch {
 	case n.err != nil:
 		// for error, return TypeError
 		return TypeError
-	case n.buf == nil || len(n.buf) == 0:
+	case len(b) == 0:
 		// for nil raw, return TypeUndefined
 		return TypeUndefined
-	case n.buf[0] == '"':
+	case b[0] == '"':
 		// simply examine the first character
-		// to determine the value type
+		// to determine the value type, except for
+		// the non-finite numbers of JSON5 input
+		if _, ok := n.nonFiniteValue(); ok {
+			switch n.limits.NonFinite {
+			case NonFiniteNull:
+				return TypeNull
+			case NonFiniteError:
+				return TypeNumber
+			}
+		}
 		return TypeString
-	case n.buf[0] == '{':
+	case b[0] == '{':
 		// simply examine the first character
 		// to determine the value type
 		return TypeObject
-	case n.buf[0] == '[':
+	case b[0] == '[':
 		// simply examine the first character
 		// to determine the value type
 		return TypeArray
-	case string(n.buf) == "true":
+	case string(b) == "true":
 		fallthrough
-	case string(n.buf) == "false":
+	case string(b) == "false":
 		return TypeBool
-	case string(n.buf) == "null":
+	case string(b) == "null":
 		return TypeNull
-	case isNumJSON(n.buf):
+	case isNumJSON(b):
 		return TypeNumber
 	}
 
@@ -147,38 +309,66 @@ func (n rootNode) Type() Type {
 	return TypeError
 }
 
-func (n *rootNode) genMapBuf() error {
+// members returns the members of the object node, parsed
+// once and cached if the node has an objectCache
+func (n *rootNode) members() (map[string]rootNode, error) {
 	if n.Type() != TypeObject {
-		return ErrorNotObject
+		return nil, ErrorNotObject
+	}
+	var members map[string]rootNode
+	var err error
+	if n.obj == nil {
+		members = map[string]rootNode{}
+		err = fromJSONError(json.Unmarshal(n.buf, &members))
+	} else {
+		n.obj.once.Do(func() {
+			n.obj.members = map[string]rootNode{}
+			n.obj.err = fromJSONError(json.Unmarshal(n.buf, &n.obj.members))
+		})
+		members, err = n.obj.members, n.obj.err
 	}
-	if n.mapBuf != nil {
-		return nil // previously done, use the previous result
+	if err == nil && n.limits != nil && n.limits.MaxKeys > 0 && len(members) > n.limits.MaxKeys {
+		return nil, ErrorLimitExceeded
 	}
-
-	// generate the map
-	n.mapBuf = map[string]rootNode{}
-	return n.Unmarshal(&n.mapBuf)
+	return members, err
 }
 
 // GetKeys get object keys of the node.
-// If the node is not an object, returns nil
+// If the node is not an object, or has more keys
+// than MaxKeys of DecodeWithOptions, returns nil
 func (n *rootNode) GetKeys() (keys []string) {
-	if err := n.genMapBuf(); err != nil {
+	members, err := n.members()
+	if err != nil {
 		return
 	}
-	keys = make([]string, 0, len(n.mapBuf))
-	for key := range n.mapBuf {
+	keys = make([]string, 0, len(members))
+	for key := range members {
 		keys = append(keys, key)
 	}
 	return
 }
 
-func (n *rootNode) keyPath(key string) string {
+// keyPath returns the path to the key in an object of the given path
+func keyPath(path, key string) string {
 	fmtKey := "." + key
 	if strings.IndexAny(key, " /-") >= 0 {
 		fmtKey = fmt.Sprintf("[%#v]", key)
 	}
-	return n.path + fmtKey
+	return path + fmtKey
+}
+
+func (n *rootNode) keyPath(key string) string {
+	return keyPath(n.path, key)
+}
+
+// inheritError returns the error of a parent node,
+// as the error of the child at the path
+func inheritError(err error, path string) error {
+	if lerr, ok := err.(Error); ok {
+		lerr.Requested = "json" + path
+		return lerr
+	}
+	return err
 }
 
 // Get implements Node
@@ -191,32 +381,34 @@ func (n *rootNode) Get(key string) (inner Node) {
 	if err := n.ParseError(); err != nil {
 		return &rootNode{
 			path: path,
-			err:  err,
+			err:  inheritError(err, path),
 		}
 	}
 
-	if err := n.genMapBuf(); err != nil {
-		if err == ErrorNotObject {
-			path = n.path // fallback to the parent entity
-		}
+	members, err := n.members()
+	if err != nil {
+		// the failure is of the parent entity
 		inner = &rootNode{
 			path: path,
 			err: Error{
-				Path: "json" + path,
-				Err:  err,
+				Path:      "json" + n.path,
+				Requested: "json" + path,
+				Err:       err,
 			},
 		}
-	} else if val, ok := n.mapBuf[key]; !ok {
+	} else if val, ok := members[key]; !ok {
 		inner = &rootNode{
 			path: path,
 			err: Error{
-				Path: "json" + path,
-				Err:  ErrorUndefined,
+				Path:      "json" + path,
+				Requested: "json" + path,
+				Err:       ErrorUndefined,
 			},
 		}
 	} else {
 		val.path = path
-		inner = &val
+		val.limits, val.depth = n.limits, n.depth+1
+		inner = val.limit()
 	}
 	return
 }
@@ -226,18 +418,26 @@ func (n *rootNode) Get(key string) (inner Node) {
 func (n *rootNode) Len() int {
 	switch n.Type() {
 	case TypeString:
-		return len(string(n.buf)) - 2 // subtact the 2 " marks
+		return len(bytes.TrimSpace(n.buf)) - 2 // subtact the 2 " marks
 	case TypeArray:
 		vslice := []*rootNode{}
-		n.Unmarshal(&vslice)
+		json.Unmarshal(n.buf, &vslice)
+		if n.limits != nil && n.limits.MaxArrayLen > 0 && len(vslice) > n.limits.MaxArrayLen {
+			return -1
+		}
 		return len(vslice)
 	}
 	// default return -1 (for type mismatch)
 	return -1
 }
 
+// nthPath returns the path to the nth item in an array of the given path
+func nthPath(path string, nth int) string {
+	return fmt.Sprintf("%s[%d]", path, nth)
+}
+
 func (n *rootNode) nthPath(nth int) string {
-	return fmt.Sprintf("%s[%d]", n.path, nth)
+	return nthPath(n.path, nth)
 }
 
 // GetN implements Node
@@ -250,31 +450,54 @@ func (n *rootNode) GetN(nth int) Node {
 	if err := n.ParseError(); err != nil {
 		return &rootNode{
 			path: path,
-			err:  err,
+			err:  inheritError(err, path),
 		}
 	}
 
 	if n.Type() != TypeArray {
 		return &rootNode{
-			path: n.path,
+			path: path,
 			err: Error{
-				Path: "json" + n.path,
-				Err:  ErrorNotArray,
+				Path:      "json" + n.path,
+				Requested: "json" + path,
+				Err:       ErrorNotArray,
 			},
 		}
 	}
 
 	vslice := []rootNode{}
-	n.Unmarshal(&vslice)
-	if nth < len(vslice) {
-		vslice[nth].path = path
-		return &vslice[nth]
+	if err := json.Unmarshal(n.buf, &vslice); err != nil {
+		return &rootNode{
+			path: path,
+			err: Error{
+				Path:      "json" + n.path,
+				Requested: "json" + path,
+				Err:       fromJSONError(err),
+			},
+		}
+	}
+	if n.limits != nil && n.limits.MaxArrayLen > 0 && len(vslice) > n.limits.MaxArrayLen {
+		return &rootNode{
+			path: path,
+			err: Error{
+				Path:      "json" + n.path,
+				Requested: "json" + path,
+				Err:       ErrorLimitExceeded,
+			},
+		}
+	}
+	if nth >= 0 && nth < len(vslice) {
+		val := vslice[nth]
+		val.path = path
+		val.limits, val.depth = n.limits, n.depth+1
+		return val.limit()
 	}
 	return &rootNode{
 		path: path,
 		err: Error{
-			Path: "json" + path,
-			Err:  ErrorUndefined,
+			Path:      "json" + path,
+			Requested: "json" + path,
+			Err:       ErrorIndexOutOfRange,
 		},
 	}
 }
@@ -286,7 +509,13 @@ func (n *rootNode) String() (v string) {
 }
 
 // Number implements Node
+//
+// The non-finite numbers of JSON5 input are returned
+// as is, whatever the NonFinite policy
 func (n *rootNode) Number() (v float64) {
+	if v, ok := n.nonFiniteValue(); ok {
+		return v
+	}
 	n.Unmarshal(&v)
 	return
 }
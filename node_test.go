@@ -3,6 +3,7 @@ package lzjson_test
 import (
 	"encoding/json"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/go-restit/lzjson"
@@ -121,6 +122,16 @@ func TestNode_Type(t *testing.T) {
 		t.Errorf("expected %s, got %s", want, have)
 	}
 
+	if want, have := lzjson.TypeObject, readJSON("\n  { \"foo\": 1 }\n").Type(); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+	if want, have := lzjson.TypeNumber, readJSON("1234\n").Type(); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+	if want, have := lzjson.TypeUndefined, readJSON(" \n").Type(); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+
 	if want, have := lzjson.TypeError, readJSON("404 not found").Type(); want != have {
 		t.Errorf("expected %s, got %s", want, have)
 	}
@@ -183,7 +194,9 @@ func TestNode_Get(t *testing.T) {
 		t.Errorf("expected %#v, got %#v", want, have)
 	} else if want, have := "four", n.GetN(3).String(); want != have {
 		t.Errorf("expected %#v, got %#v", want, have)
-	} else if want, have := lzjson.ErrorUndefined, n.GetN(4).ParseError().(lzjson.Error).Err; want != have {
+	} else if want, have := lzjson.ErrorIndexOutOfRange, n.GetN(4).ParseError().(lzjson.Error).Err; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	} else if want, have := lzjson.ErrorIndexOutOfRange, n.GetN(-1).ParseError().(lzjson.Error).Err; want != have {
 		t.Errorf("expected %#v, got %#v", want, have)
 	} else if want, have := false, n.Bool(); want != have {
 		t.Errorf("expected %#v, got %#v", want, have)
@@ -244,7 +257,7 @@ func TestNode_Get_error(t *testing.T) {
 	}
 	if n.ParseError() == nil {
 		t.Error("expected error, got nil")
-	} else if want, have := "json: not an object", n.ParseError().Error(); want != have {
+	} else if want, have := `json["hello key"]: type mismatch (json is not an object)`, n.ParseError().Error(); want != have {
 		t.Errorf("expected %#v, got %#v", want, have)
 	}
 
@@ -254,7 +267,7 @@ func TestNode_Get_error(t *testing.T) {
 	}
 	if n.ParseError() == nil {
 		t.Error("expected error, got nil")
-	} else if want, have := "json: not an object", n.ParseError().Error(); want != have {
+	} else if want, have := "json.hello: type mismatch (json is not an object)", n.ParseError().Error(); want != have {
 		t.Errorf("expected %#v, got %#v", want, have)
 	}
 
@@ -271,10 +284,34 @@ func TestNode_Get_error(t *testing.T) {
 	} else if want, have := "json.foo: undefined", n.ParseError().Error(); want != have {
 		t.Errorf("expected %#v, got %#v", want, have)
 	}
-	if want, have := "json.foo: undefined", root.Get("foo").Get("hello").ParseError().Error(); want != have {
+	if want, have := "json.foo.hello: undefined (json.foo not found)", root.Get("foo").Get("hello").ParseError().Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+
+	// the error records both the requested path and the failing path
+	lerr := root.Get("foo").Get("hello").GetN(3).ParseError().(lzjson.Error)
+	if want, have := "json.foo", lerr.Path; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := "json.foo.hello[3]", lerr.Requested; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := lzjson.ErrorUndefined, lerr.Err; want != have {
 		t.Errorf("expected %#v, got %#v", want, have)
 	}
 
+	// the syntax error of a malformed object is at the object
+	root = lzjson.ParseString(`{"a": 1, }`)
+	lerr = root.Get("a").ParseError().(lzjson.Error)
+	if want, have := "json", lerr.Path; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := "json.a", lerr.Requested; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := "json.a.b: syntax error (json: invalid character '}' looking for beginning of object key string at offset 10)", root.Get("a").Get("b").ParseError().Error(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
 }
 
 func TestNode_GetN_error(t *testing.T) {
@@ -289,7 +326,7 @@ func TestNode_GetN_error(t *testing.T) {
 	}
 	if n.ParseError() == nil {
 		t.Error("expected error, got nil")
-	} else if want, have := "json: not an array", n.ParseError().Error(); want != have {
+	} else if want, have := "json[1]: type mismatch (json is not an array)", n.ParseError().Error(); want != have {
 		t.Errorf("expected %#v, got %#v", want, have)
 	}
 
@@ -300,13 +337,13 @@ func TestNode_GetN_error(t *testing.T) {
 	}
 	if n.ParseError() == nil {
 		t.Error("expected error, got nil")
-	} else if want, have := "json[2]: undefined", n.ParseError().Error(); want != have {
+	} else if want, have := "json[2]: index out of range", n.ParseError().Error(); want != have {
 		t.Errorf("expected %#v, got %#v", want, have)
 	}
-	if want, have := "json: not an object", root.Get("hello").GetN(0).ParseError().Error(); want != have {
+	if want, have := "json.hello[0]: type mismatch (json is not an object)", root.Get("hello").GetN(0).ParseError().Error(); want != have {
 		t.Errorf("expected %#v, got %#v", want, have)
 	}
-	if want, have := "json[3]: undefined", root.GetN(3).GetN(0).ParseError().Error(); want != have {
+	if want, have := "json[3][0]: undefined (json[3] out of range)", root.GetN(3).GetN(0).ParseError().Error(); want != have {
 		t.Errorf("expected %#v, got %#v", want, have)
 	}
 
@@ -349,3 +386,243 @@ func TestNode_GetKeys(t *testing.T) {
 		t.Errorf("expected keys[1] to be %#v, got %#v", want, have)
 	}
 }
+
+func TestNode_MarshalJSON(t *testing.T) {
+	type inner struct {
+		Name string      `json:"name"`
+		Data lzjson.Node `json:"data"`
+	}
+	type outer struct {
+		ID    int         `json:"id"`
+		Inner inner       `json:"inner"`
+		Extra lzjson.Node `json:"extra"`
+	}
+
+	str := `{"id":1,"inner":{"name":"foo","data":{"hello":["world",1,true,null]}},"extra":"bar"}`
+
+	v := outer{
+		Inner: inner{Data: lzjson.NewNode()},
+		Extra: lzjson.NewNode(),
+	}
+	if err := json.Unmarshal([]byte(str), &v); err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+		return
+	}
+	if want, have := `{"hello":["world",1,true,null]}`, string(v.Inner.Data.Raw()); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := "world", v.Inner.Data.Get("hello").GetN(0).String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+		return
+	}
+	if want, have := str, string(b); want != have {
+		t.Errorf("\nexpected: %s\ngot:      %s", want, have)
+	}
+}
+
+func TestNode_MarshalJSON_indented(t *testing.T) {
+	type wrapper struct {
+		Data lzjson.Node `json:"data"`
+	}
+	v := wrapper{Data: lzjson.Decode(strings.NewReader(dummyJSONStr()))}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+		return
+	}
+	if want, have := 1234.56, lzjson.Decode(strings.NewReader(string(b))).Get("data").Get("number").Number(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if strings.ContainsAny(string(b), "\n\t") {
+		t.Errorf("expected compacted output, got %s", b)
+	}
+}
+
+func TestNode_MarshalJSON_undefined(t *testing.T) {
+	b, err := json.Marshal(map[string]lzjson.Node{
+		"foo": lzjson.NewNode(),
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+		return
+	}
+	if want, have := `{"foo":null}`, string(b); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
+
+func TestNode_MarshalJSON_error(t *testing.T) {
+	root := lzjson.Decode(strings.NewReader(`{"hello": "world"}`))
+	_, err := json.Marshal(map[string]lzjson.Node{
+		"foo": root.Get("foo"),
+	})
+	if err == nil {
+		t.Error("expected error, got nil")
+	} else if want, have := "json.foo: undefined", err.Error(); !strings.Contains(have, want) {
+		t.Errorf("expected error to contain %#v, got %#v", want, have)
+	}
+
+	_, err = json.Marshal(map[string]lzjson.Node{
+		"foo": lzjson.Decode(strings.NewReader("404 not found")),
+	})
+	if err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestParse(t *testing.T) {
+	b := []byte(dummyJSONStr())
+	n := lzjson.Parse(b)
+	if err := n.ParseError(); err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+	if want, have := lzjson.TypeObject, n.Type(); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+	if want, have := "foo bar", n.Get("string").String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := &b[0], &n.Raw()[0]; want != have {
+		t.Errorf("expected Raw() to share the input bytes")
+	}
+}
+
+func TestParseString(t *testing.T) {
+	n := lzjson.ParseString(`[1, "two", 3]`)
+	if want, have := lzjson.TypeArray, n.Type(); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+	if want, have := 3, n.Len(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := "two", n.GetN(1).String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := lzjson.TypeUndefined, lzjson.ParseString("").Type(); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+}
+
+func TestFromValue(t *testing.T) {
+	type thing struct {
+		Name  string   `json:"name"`
+		Tags  []string `json:"tags"`
+		Count int      `json:"count,omitempty"`
+	}
+
+	n, err := lzjson.FromValue(thing{Name: "foo", Tags: []string{"a", "b"}})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+		return
+	}
+	if want, have := `{"name":"foo","tags":["a","b"]}`, string(n.Raw()); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := "b", n.Get("tags").GetN(1).String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := lzjson.TypeError, n.Get("count").Type(); want != have {
+		t.Errorf("expected %s, got %s", want, have)
+	}
+
+	// a Node is itself a valid value
+	n2, err := lzjson.FromValue(map[string]interface{}{"inner": n})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+		return
+	}
+	if want, have := "foo", n2.Get("inner").Get("name").String(); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+
+	if _, err := lzjson.FromValue(make(chan int)); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	n := lzjson.ParseString(`{"foo": {"bar": [1, {"hello world": "hi"}]}}`)
+
+	tests := []struct {
+		Sel  string
+		Raw  string
+		Path string
+	}{
+		{``, `{"foo": {"bar": [1, {"hello world": "hi"}]}}`, ""},
+		{`foo.bar[0]`, `1`, ""},
+		{`foo.bar[1]["hello world"]`, `"hi"`, ""},
+		{`foo.baz`, ``, "json.foo.baz: undefined"},
+		{`foo.bar[2]`, ``, "json.foo.bar[2]: index out of range"},
+		{`foo.bar.baz`, ``, "json.foo.bar.baz: type mismatch (json.foo.bar is not an object)"},
+		{`foo[0]`, ``, "json.foo[0]: type mismatch (json.foo is not an array)"},
+	}
+	for _, test := range tests {
+		inner, err := lzjson.Select(n, test.Sel)
+		if err != nil {
+			t.Errorf("sel=%#v unexpected error: %s", test.Sel, err.Error())
+			continue
+		}
+		if want, have := test.Raw, string(inner.Raw()); want != have {
+			t.Errorf("sel=%#v expected %#v, got %#v", test.Sel, want, have)
+		}
+		if err := inner.ParseError(); err != nil {
+			if want, have := test.Path, err.Error(); want != have {
+				t.Errorf("sel=%#v expected %#v, got %#v", test.Sel, want, have)
+			}
+		} else if test.Path != "" {
+			t.Errorf("sel=%#v expected error %#v, got nil", test.Sel, test.Path)
+		}
+	}
+
+	if _, err := lzjson.Select(n, `foo[`); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestNode_concurrent(t *testing.T) {
+	// run with -race to detect data races. t.Errorf is safe
+	// to call from the goroutines, unlike t.Fatalf
+	nodes := map[string]lzjson.Node{
+		"Decode":      lzjson.Decode(strings.NewReader(dummyJSONStr())),
+		"ParseString": lzjson.ParseString(dummyJSONStr()),
+		"NewNode":     lzjson.NewNode(),
+	}
+	json.Unmarshal([]byte(dummyJSONStr()), nodes["NewNode"])
+	edited, err := nodes["ParseString"].Set("object.foo", "baz")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	nodes["Set"] = edited
+
+	for name, n := range nodes {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					if have := len(n.GetKeys()); have != 7 {
+						t.Errorf("%s: expected 7 keys, got %d", name, have)
+					}
+					if have := n.Get("object").Get("answer").Int(); have != 42 {
+						t.Errorf("%s: expected 42, got %d", name, have)
+					}
+					if have := n.Get("arrayOfString").GetN(j % 4).Type(); have != lzjson.TypeString {
+						t.Errorf("%s: expected %s, got %s", name, lzjson.TypeString, have)
+					}
+					if have := n.Get("object").GetKeys(); len(have) != 3 {
+						t.Errorf("%s: expected 3 keys, got %#v", name, have)
+					}
+					n.Get("undefined").ParseError()
+					n.Get("arrayOfString").Len()
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
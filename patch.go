@@ -0,0 +1,281 @@
+package lzjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PatchError describes a failed operation of JSON Patch
+type PatchError struct {
+	Index int    // index of the operation in the patch
+	Op    string // name of the operation
+	Path  string // JSON Pointer of the operation target
+	Err   error
+}
+
+// Error implements error type
+func (err PatchError) Error() string {
+	return fmt.Sprintf("operation %d (%s %#v): %s", err.Index, err.Op, err.Path, err.Err.Error())
+}
+
+// Unwrap returns the underlying error, for errors.Is and errors.As
+func (err PatchError) Unwrap() error {
+	return err.Err
+}
+
+// patchOp is a parsed JSON Patch operation
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// parsePointer parses a JSON Pointer (RFC 6901) into tokens
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return []string{}, nil
+	}
+	if ptr[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %#v", ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// pointerIndex parses a JSON Pointer token as array index.
+// Returns -1 if the token is not a valid index
+func pointerIndex(token string) int {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return -1
+	}
+	nth, err := strconv.Atoi(token)
+	if err != nil || nth < 0 || token[0] == '+' {
+		return -1
+	}
+	return nth
+}
+
+// locatePointer walks the pointer tokens from the root value of b,
+// then returns the offsets and the path of the selected value
+func locatePointer(b []byte, tokens []string) (start, end int, path string, err error) {
+	start = skipSpace(b, 0)
+	if end, err = scanValue(b, start); err != nil {
+		err = Error{Path: "json", Err: err}
+		return
+	}
+	for _, token := range tokens {
+		switch b[start] {
+		case '{':
+			members, _, _ := scanObject(b, start)
+			path = keyPath(path, token)
+			i := lastMember(members, token)
+			if i < 0 {
+				err = Error{Path: "json" + path, Err: ErrorUndefined}
+				return
+			}
+			start, end = members[i].valStart, members[i].valEnd
+		case '[':
+			elems, _, _ := scanArray(b, start)
+			nth := pointerIndex(token)
+			if nth < 0 {
+				err = Error{Path: "json" + path, Err: fmt.Errorf("invalid array index %#v", token)}
+				return
+			}
+			path = nthPath(path, nth)
+			if nth >= len(elems) {
+				err = Error{Path: "json" + path, Err: ErrorIndexOutOfRange}
+				return
+			}
+			start, end = elems[nth].start, elems[nth].end
+		default:
+			err = Error{Path: "json" + path, Err: ErrorNotObject}
+			return
+		}
+	}
+	return
+}
+
+// patchAdd adds the value to the location of the pointer tokens
+func patchAdd(b []byte, tokens []string, v []byte) ([]byte, error) {
+	if len(tokens) == 0 {
+		return v, nil
+	}
+	start, _, path, err := locatePointer(b, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch b[start] {
+	case '{':
+		return setMember(b, start, last, v), nil
+	case '[':
+		l := arrayLen(b, start)
+		if last == "-" {
+			return insertElem(b, start, l, v), nil
+		}
+		nth := pointerIndex(last)
+		if nth < 0 {
+			return nil, Error{Path: "json" + path, Err: fmt.Errorf("invalid array index %#v", last)}
+		} else if nth > l {
+			return nil, Error{Path: "json" + nthPath(path, nth), Err: ErrorIndexOutOfRange}
+		}
+		return insertElem(b, start, nth, v), nil
+	}
+	return nil, Error{Path: "json" + path, Err: ErrorNotObject}
+}
+
+// patchRemove removes the value at the location of the pointer tokens
+func patchRemove(b []byte, tokens []string) ([]byte, error) {
+	if len(tokens) == 0 {
+		return nil, Error{Path: "json", Err: errors.New("cannot remove the root value")}
+	}
+	// make sure the target exists
+	if _, _, _, err := locatePointer(b, tokens); err != nil {
+		return nil, err
+	}
+	start, _, _, _ := locatePointer(b, tokens[:len(tokens)-1])
+	last := tokens[len(tokens)-1]
+	if b[start] == '[' {
+		return deleteElem(b, start, pointerIndex(last)), nil
+	}
+	return deleteMember(b, start, last), nil
+}
+
+// applyOp applies a single operation to b
+func applyOp(b []byte, op patchOp) ([]byte, error) {
+	tokens, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+	var from []string
+	if op.From != nil {
+		if from, err = parsePointer(*op.From); err != nil {
+			return nil, err
+		}
+	}
+
+	switch op.Op {
+	case "add":
+		return patchAdd(b, tokens, op.Value)
+	case "remove":
+		return patchRemove(b, tokens)
+	case "replace":
+		start, end, _, err := locatePointer(b, tokens)
+		if err != nil {
+			return nil, err
+		}
+		return splice(b, start, end, op.Value), nil
+	case "move":
+		if *op.From == *op.Path {
+			return b, nil
+		}
+		if strings.HasPrefix(*op.Path, *op.From+"/") {
+			return nil, errors.New("cannot move a value into one of its children")
+		}
+		start, end, _, err := locatePointer(b, from)
+		if err != nil {
+			return nil, err
+		}
+		v := append([]byte(nil), b[start:end]...)
+		if b, err = patchRemove(b, from); err != nil {
+			return nil, err
+		}
+		return patchAdd(b, tokens, v)
+	case "copy":
+		start, end, _, err := locatePointer(b, from)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(b, tokens, append([]byte(nil), b[start:end]...))
+	case "test":
+		start, end, path, err := locatePointer(b, tokens)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(b[start:end], op.Value) {
+			return nil, Error{Path: "json" + path, Err: fmt.Errorf("test failed: expected %s, got %s", compactRaw(op.Value), b[start:end])}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("unknown operation %#v", op.Op)
+}
+
+// validateOp checks if the operation has all its required members
+func validateOp(op patchOp) error {
+	switch {
+	case op.Path == nil:
+		return errors.New(`missing "path"`)
+	case (op.Op == "add" || op.Op == "replace" || op.Op == "test") && op.Value == nil:
+		return errors.New(`missing "value"`)
+	case (op.Op == "move" || op.Op == "copy") && op.From == nil:
+		return errors.New(`missing "from"`)
+	}
+	return nil
+}
+
+// applyPatch applies the JSON Patch to doc
+func applyPatch(doc, patch Node) ([]byte, error) {
+	b, err := rawValue(doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := patch.ParseError(); err != nil {
+		return nil, err
+	}
+	var ops []patchOp
+	if patch.Type() != TypeArray {
+		return nil, Error{Path: "json", Err: ErrorNotArray}
+	}
+	if err := patch.Unmarshal(&ops); err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		perr := PatchError{Index: i, Op: op.Op}
+		if op.Path != nil {
+			perr.Path = *op.Path
+		}
+		if err = validateOp(op); err == nil {
+			b, err = applyOp(b, op)
+		}
+		if err != nil {
+			// annotate the error with the operation
+			// but keep the path of the document
+			if lerr, ok := err.(Error); ok {
+				perr.Err = lerr.Err
+				return nil, Error{Path: lerr.Path, Err: perr}
+			}
+			perr.Err = err
+			return nil, Error{Err: perr}
+		}
+	}
+	return b, nil
+}
+
+// ApplyPatch applies a JSON Patch (RFC 6902) to the doc and
+// returns the result as a new Node. The doc is not modified.
+//
+// Operations are applied in order. If any of them fails, ApplyPatch
+// returns an Error with the Path of the target in doc, and a
+// PatchError naming the failing operation
+func ApplyPatch(doc, patch Node) (Node, error) {
+	b, err := applyPatch(doc, patch)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b), nil
+}
+
+// ValidatePatch does a dry-run of ApplyPatch. It reports the
+// same error ApplyPatch would, without producing the result
+func ValidatePatch(doc, patch Node) error {
+	_, err := applyPatch(doc, patch)
+	return err
+}
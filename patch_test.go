@@ -0,0 +1,150 @@
+package lzjson_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+// patchCases are mostly the examples in RFC 6902 Appendix A
+var patchCases = []struct {
+	Doc    string
+	Patch  string
+	Result string
+}{
+	// A.1. Adding an Object Member
+	{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux"}]`, `{"foo": "bar", "baz": "qux"}`},
+	// A.2. Adding an Array Element
+	{`{"foo": ["bar", "baz"]}`, `[{"op": "add", "path": "/foo/1", "value": "qux"}]`, `{"foo": ["bar", "qux", "baz"]}`},
+	// A.3. Removing an Object Member
+	{`{"baz": "qux", "foo": "bar"}`, `[{"op": "remove", "path": "/baz"}]`, `{"foo": "bar"}`},
+	// A.4. Removing an Array Element
+	{`{"foo": ["bar", "qux", "baz"]}`, `[{"op": "remove", "path": "/foo/1"}]`, `{"foo": ["bar", "baz"]}`},
+	// A.5. Replacing a Value
+	{`{"baz": "qux", "foo": "bar"}`, `[{"op": "replace", "path": "/baz", "value": "boo"}]`, `{"baz": "boo", "foo": "bar"}`},
+	// A.6. Moving a Value
+	{
+		`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+		`[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+		`{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+	},
+	// A.7. Moving an Array Element
+	{`{"foo": ["all", "grass", "cows", "eat"]}`, `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`, `{"foo": ["all", "cows", "eat", "grass"]}`},
+	// A.8. Testing a Value: Success
+	{
+		`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		`[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+		`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+	},
+	// A.10. Adding a Nested Member Object
+	{`{"foo": "bar"}`, `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`, `{"foo": "bar", "child": {"grandchild": {}}}`},
+	// A.11. Ignoring Unrecognized Elements
+	{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`, `{"foo": "bar", "baz": "qux"}`},
+	// A.14. ~ Escape Ordering
+	{`{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": 10}]`, `{"/": 9, "~1": 10}`},
+	// A.16. Adding an Array Value
+	{`{"foo": ["bar"]}`, `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`, `{"foo": ["bar",["abc", "def"]]}`},
+	// copy and replacing the root
+	{`{"a": {"b": 1}}`, `[{"op": "copy", "from": "/a", "path": "/c"}]`, `{"a": {"b": 1}, "c": {"b": 1}}`},
+	{`{"a": 1}`, `[{"op": "replace", "path": "", "value": [1]}]`, `[1]`},
+	{`{"a": 1}`, `[]`, `{"a": 1}`},
+}
+
+func TestApplyPatch(t *testing.T) {
+	for _, test := range patchCases {
+		n, err := lzjson.ApplyPatch(lzjson.ParseString(test.Doc), lzjson.ParseString(test.Patch))
+		if err != nil {
+			t.Errorf("doc=%s patch=%s unexpected error: %s", test.Doc, test.Patch, err.Error())
+			continue
+		}
+		if want, have := test.Result, string(n.Raw()); want != have {
+			t.Errorf("doc=%s patch=%s\nexpected: %s\ngot:      %s", test.Doc, test.Patch, want, have)
+		}
+	}
+}
+
+func TestApplyPatch_error(t *testing.T) {
+	tests := []struct {
+		Doc   string
+		Patch string
+		Err   string
+	}{
+		// A.9. Testing a Value: Error
+		{`{"baz": "qux"}`, `[{"op": "test", "path": "/baz", "value": "bar"}]`, `json.baz: operation 0 (test "/baz"): test failed: expected "bar", got "qux"`},
+		// A.12. Adding to a Nonexistent Target
+		{`{"foo": "bar"}`, `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`, `json.baz: operation 0 (add "/baz/bat"): undefined`},
+		// A.15. Comparing Strings and Numbers
+		{`{"/": 9, "~1": 10}`, `[{"op": "test", "path": "/~01", "value": "10"}]`, `json.~1: operation 0 (test "/~01"): test failed: expected "10", got 10`},
+		{`{"a": [1]}`, `[{"op": "test", "path": "/a/0", "value": 1}, {"op": "remove", "path": "/a/1"}]`, `json.a[1]: operation 1 (remove "/a/1"): index out of range`},
+		{`{"a": [1]}`, `[{"op": "add", "path": "/a/2", "value": 2}]`, `json.a[2]: operation 0 (add "/a/2"): index out of range`},
+		{`{"a": [1]}`, `[{"op": "add", "path": "/a/01", "value": 2}]`, `json.a: operation 0 (add "/a/01"): invalid array index "01"`},
+		{`{"a": "b"}`, `[{"op": "replace", "path": "/a/b", "value": 2}]`, `json.a: operation 0 (replace "/a/b"): not an object`},
+		{`{"a": {}}`, `[{"op": "move", "from": "/a", "path": "/a/b"}]`, `operation 0 (move "/a/b"): cannot move a value into one of its children`},
+		{`{"a": 1}`, `[{"op": "copy", "path": "/b"}]`, `operation 0 (copy "/b"): missing "from"`},
+		{`{"a": 1}`, `[{"op": "add", "path": "/b"}]`, `operation 0 (add "/b"): missing "value"`},
+		{`{"a": 1}`, `[{"op": "add", "value": 1}]`, `operation 0 (add ""): missing "path"`},
+		{`{"a": 1}`, `[{"op": "upsert", "path": "/a", "value": 1}]`, `operation 0 (upsert "/a"): unknown operation "upsert"`},
+		{`{"a": 1}`, `[{"op": "remove", "path": "a"}]`, `operation 0 (remove "a"): invalid JSON pointer "a"`},
+		{`{"a": 1}`, `[{"op": "remove", "path": ""}]`, `json: operation 0 (remove ""): cannot remove the root value`},
+		{`{"a": 1}`, `{"op": "remove", "path": "/a"}`, `json: not an array`},
+	}
+	for _, test := range tests {
+		doc, patch := lzjson.ParseString(test.Doc), lzjson.ParseString(test.Patch)
+		_, err := lzjson.ApplyPatch(doc, patch)
+		if err == nil {
+			t.Errorf("doc=%s patch=%s expected error, got nil", test.Doc, test.Patch)
+			continue
+		}
+		if want, have := test.Err, err.Error(); want != have {
+			t.Errorf("doc=%s patch=%s\nexpected: %s\ngot:      %s", test.Doc, test.Patch, want, have)
+		}
+		if want, have := err.Error(), lzjson.ValidatePatch(doc, patch); have == nil || want != have.Error() {
+			t.Errorf("doc=%s patch=%s expected dry-run error %#v, got %#v", test.Doc, test.Patch, want, have)
+		}
+	}
+}
+
+func TestApplyPatch_errorType(t *testing.T) {
+	doc := lzjson.ParseString(`{"a": [1, 2]}`)
+	patch := lzjson.ParseString(`[
+		{"op": "remove", "path": "/a/0"},
+		{"op": "remove", "path": "/a/1"}
+	]`)
+	_, err := lzjson.ApplyPatch(doc, patch)
+	lerr, ok := err.(lzjson.Error)
+	if !ok {
+		t.Errorf("expected lzjson.Error, got %#v", err)
+		return
+	}
+	if want, have := "json.a[1]", lerr.Path; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	perr, ok := lerr.Err.(lzjson.PatchError)
+	if !ok {
+		t.Errorf("expected lzjson.PatchError, got %#v", lerr.Err)
+		return
+	}
+	if want, have := 1, perr.Index; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := "/a/1", perr.Path; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := lzjson.ErrorIndexOutOfRange, perr.Err; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if !errors.Is(err, lzjson.ErrorUndefined) {
+		t.Errorf("expected errors.Is(err, lzjson.ErrorUndefined)")
+	}
+}
+
+func TestValidatePatch(t *testing.T) {
+	doc := lzjson.ParseString(`{"a": 1}`)
+	if err := lzjson.ValidatePatch(doc, lzjson.ParseString(`[{"op": "add", "path": "/b", "value": 2}]`)); err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+	if want, have := `{"a": 1}`, string(doc.Raw()); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
@@ -0,0 +1,188 @@
+package lzjson
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ANSI escape codes used by Render
+const (
+	ansiReset     = "\x1b[0m"
+	ansiKey       = "\x1b[34m"   // blue
+	ansiString    = "\x1b[32m"   // green
+	ansiNumber    = "\x1b[36m"   // cyan
+	ansiBool      = "\x1b[33m"   // yellow
+	ansiNull      = "\x1b[35m"   // magenta
+	ansiFaint     = "\x1b[90m"   // gray
+	ansiHighlight = "\x1b[1;41m" // bold on red
+)
+
+// renderConfig holds the options of Render
+type renderConfig struct {
+	noColor      bool
+	maxDepth     int
+	maxStringLen int
+	highlight    string
+}
+
+// RenderOption configures Render
+type RenderOption func(*renderConfig)
+
+// RenderNoColor renders without ANSI escape codes
+func RenderNoColor() RenderOption {
+	return func(c *renderConfig) {
+		c.noColor = true
+	}
+}
+
+// RenderMaxDepth truncates objects and arrays nested deeper
+// than the depth into a summary (e.g. `{…12 keys}`).
+// The root value is at depth 0
+func RenderMaxDepth(depth int) RenderOption {
+	return func(c *renderConfig) {
+		c.maxDepth = depth
+	}
+}
+
+// RenderMaxStringLen elides strings longer than the given
+// number of characters
+func RenderMaxStringLen(l int) RenderOption {
+	return func(c *renderConfig) {
+		c.maxStringLen = l
+	}
+}
+
+// RenderHighlightPath highlights the value at the path, as in
+// the Path of Error (e.g. `json.foo.bar[3]`). Objects and
+// arrays containing the value are never truncated
+func RenderHighlightPath(path string) RenderOption {
+	return func(c *renderConfig) {
+		c.highlight = strings.TrimPrefix(path, "json")
+	}
+}
+
+// renderer writes rendered JSON values to w
+type renderer struct {
+	*renderConfig
+	w *bufio.Writer
+}
+
+// Render writes the node to w in indented form with ANSI
+// colors by type, for reading in terminal. Undefined node,
+// node with parse error or invalid JSON value results in
+// error without writing anything
+func Render(w io.Writer, n Node, opts ...RenderOption) error {
+	b, err := definedValue(n)
+	if err != nil {
+		return err
+	}
+
+	c := &renderConfig{maxDepth: -1}
+	for _, opt := range opts {
+		opt(c)
+	}
+	r := &renderer{renderConfig: c, w: bufio.NewWriter(w)}
+	r.render(b, nodePath(n), 0, false)
+	r.w.WriteByte('\n')
+	return r.w.Flush()
+}
+
+// write writes the text in the color, or in the
+// highlight color if highlighted
+func (r *renderer) write(color, text string, highlighted bool) {
+	if highlighted {
+		color = ansiHighlight
+	}
+	if r.noColor || color == "" {
+		r.w.WriteString(text)
+		return
+	}
+	r.w.WriteString(color + text + ansiReset)
+}
+
+// contains tells if the path contains the highlighted path
+func (r *renderer) contains(path string) bool {
+	if r.highlight == "" || !strings.HasPrefix(r.highlight, path) {
+		return false
+	}
+	rest := r.highlight[len(path):]
+	return rest != "" && (rest[0] == '.' || rest[0] == '[')
+}
+
+// plural formats the count of the noun
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// render writes the validated raw JSON value at the path
+func (r *renderer) render(b []byte, path string, depth int, highlighted bool) {
+	highlighted = highlighted || (r.highlight != "" && path == r.highlight)
+	indent := strings.Repeat("  ", depth)
+	truncate := r.maxDepth >= 0 && depth > r.maxDepth && !r.contains(path)
+
+	switch {
+	case b[0] == '{':
+		members, _, _ := scanObject(b, 0)
+		switch {
+		case len(members) == 0:
+			r.write("", "{}", highlighted)
+		case truncate:
+			r.write(ansiFaint, "{…"+plural(len(members), "key")+"}", highlighted)
+		default:
+			r.write("", "{", highlighted)
+			for i, m := range members {
+				r.w.WriteString("\n" + indent + "  ")
+				p := keyPath(path, m.key)
+				r.write(ansiKey, string(b[m.keyStart:m.keyEnd]), highlighted || p == r.highlight)
+				r.write("", ": ", highlighted)
+				r.render(b[m.valStart:m.valEnd], p, depth+1, highlighted)
+				if i < len(members)-1 {
+					r.write("", ",", highlighted)
+				}
+			}
+			r.w.WriteString("\n" + indent)
+			r.write("", "}", highlighted)
+		}
+	case b[0] == '[':
+		elems, _, _ := scanArray(b, 0)
+		switch {
+		case len(elems) == 0:
+			r.write("", "[]", highlighted)
+		case truncate:
+			r.write(ansiFaint, "[…"+plural(len(elems), "item")+"]", highlighted)
+		default:
+			r.write("", "[", highlighted)
+			for i, e := range elems {
+				r.w.WriteString("\n" + indent + "  ")
+				r.render(b[e.start:e.end], nthPath(path, i), depth+1, highlighted)
+				if i < len(elems)-1 {
+					r.write("", ",", highlighted)
+				}
+			}
+			r.w.WriteString("\n" + indent)
+			r.write("", "]", highlighted)
+		}
+	case b[0] == '"':
+		str := unquote(b)
+		if r.maxStringLen <= 0 || utf8.RuneCountInString(str) <= r.maxStringLen {
+			r.write(ansiString, string(b), highlighted)
+			return
+		}
+		var buf bytes.Buffer
+		writeCanonicalString(&buf, string([]rune(str)[:r.maxStringLen])+"…")
+		r.write(ansiString, buf.String(), highlighted)
+	case isNumStart(b[0]):
+		r.write(ansiNumber, string(b), highlighted)
+	case b[0] == 'n':
+		r.write(ansiNull, string(b), highlighted)
+	default:
+		r.write(ansiBool, string(b), highlighted)
+	}
+}
@@ -0,0 +1,140 @@
+package lzjson_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+func TestRender(t *testing.T) {
+	n := lzjson.ParseString(`{"name":"John","age":42,"admin":true,"tags":["a",null],"meta":{}}`)
+	var buf bytes.Buffer
+	if err := lzjson.Render(&buf, n); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	want := "{\n" +
+		"  \x1b[34m\"name\"\x1b[0m: \x1b[32m\"John\"\x1b[0m,\n" +
+		"  \x1b[34m\"age\"\x1b[0m: \x1b[36m42\x1b[0m,\n" +
+		"  \x1b[34m\"admin\"\x1b[0m: \x1b[33mtrue\x1b[0m,\n" +
+		"  \x1b[34m\"tags\"\x1b[0m: [\n" +
+		"    \x1b[32m\"a\"\x1b[0m,\n" +
+		"    \x1b[35mnull\x1b[0m\n" +
+		"  ],\n" +
+		"  \x1b[34m\"meta\"\x1b[0m: {}\n" +
+		"}\n"
+	if have := buf.String(); want != have {
+		t.Errorf("expected:\n%q\ngot:\n%q", want, have)
+	}
+}
+
+func TestRender_options(t *testing.T) {
+	n := lzjson.ParseString(`{
+		"data": [
+			{"id": 1, "tags": ["a", "b"], "owner": {"name": "John"}},
+			{"id": 2, "tags": ["c"], "owner": {"name": "Mary", "email": "mary@example.com"}}
+		],
+		"message": "The quick brown fox jumps over the lazy dog"
+	}`)
+
+	tests := []struct {
+		Opts []lzjson.RenderOption
+		Want string
+	}{
+		{
+			[]lzjson.RenderOption{lzjson.RenderNoColor(), lzjson.RenderMaxDepth(0)},
+			"{\n  \"data\": […2 items],\n  \"message\": \"The quick brown fox jumps over the lazy dog\"\n}\n",
+		},
+		{
+			[]lzjson.RenderOption{lzjson.RenderNoColor(), lzjson.RenderMaxDepth(1), lzjson.RenderMaxStringLen(9)},
+			"{\n  \"data\": [\n    {…3 keys},\n    {…3 keys}\n  ],\n  \"message\": \"The quick…\"\n}\n",
+		},
+		{
+			[]lzjson.RenderOption{lzjson.RenderNoColor(), lzjson.RenderMaxDepth(1), lzjson.RenderHighlightPath("json.data[1].owner.email")},
+			"{\n" +
+				"  \"data\": [\n" +
+				"    {…3 keys},\n" +
+				"    {\n" +
+				"      \"id\": 2,\n" +
+				"      \"tags\": […1 item],\n" +
+				"      \"owner\": {\n" +
+				"        \"name\": \"Mary\",\n" +
+				"        \"email\": \"mary@example.com\"\n" +
+				"      }\n" +
+				"    }\n" +
+				"  ],\n" +
+				"  \"message\": \"The quick brown fox jumps over the lazy dog\"\n" +
+				"}\n",
+		},
+		{
+			[]lzjson.RenderOption{lzjson.RenderMaxDepth(0), lzjson.RenderMaxStringLen(3), lzjson.RenderHighlightPath("json.data")},
+			"{\n" +
+				"  \x1b[1;41m\"data\"\x1b[0m: \x1b[1;41m[…2 items]\x1b[0m,\n" +
+				"  \x1b[34m\"message\"\x1b[0m: \x1b[32m\"The…\"\x1b[0m\n" +
+				"}\n",
+		},
+	}
+	for i, test := range tests {
+		var buf bytes.Buffer
+		if err := lzjson.Render(&buf, n, test.Opts...); err != nil {
+			t.Errorf("test %d: unexpected error: %s", i, err.Error())
+			continue
+		}
+		if want, have := test.Want, buf.String(); want != have {
+			t.Errorf("test %d: expected:\n%q\ngot:\n%q", i, want, have)
+		}
+	}
+}
+
+func TestRender_highlight(t *testing.T) {
+	n := lzjson.ParseString(`{"a": {"b": [1, 2]}, "c": 3}`)
+	err := n.Get("a").Get("b").GetN(5).ParseError().(lzjson.Error)
+
+	var buf bytes.Buffer
+	if err := lzjson.Render(&buf, n.Get("a"), lzjson.RenderHighlightPath("json.a.b")); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	want := "{\n" +
+		"  \x1b[1;41m\"b\"\x1b[0m: \x1b[1;41m[\x1b[0m\n" +
+		"    \x1b[1;41m1\x1b[0m\x1b[1;41m,\x1b[0m\n" +
+		"    \x1b[1;41m2\x1b[0m\n" +
+		"  \x1b[1;41m]\x1b[0m\n" +
+		"}\n"
+	if have := buf.String(); want != have {
+		t.Errorf("expected:\n%q\ngot:\n%q", want, have)
+	}
+
+	// path of an error with no value to highlight
+	buf.Reset()
+	if err := lzjson.Render(&buf, n, lzjson.RenderNoColor(), lzjson.RenderHighlightPath(err.Path)); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	if want, have := "{\n  \"a\": {\n    \"b\": [\n      1,\n      2\n    ]\n  },\n  \"c\": 3\n}\n", buf.String(); want != have {
+		t.Errorf("expected:\n%q\ngot:\n%q", want, have)
+	}
+}
+
+func TestRender_error(t *testing.T) {
+	tests := []struct {
+		Node lzjson.Node
+		Err  string
+	}{
+		{lzjson.ParseString(`{"a": 1}`).Get("b"), "json.b: undefined"},
+		{lzjson.ParseString(`{"a": 1}`).Get("a").Get("b"), "json.a.b: type mismatch (json.a is not an object)"},
+		{lzjson.ParseString(`{"a": [1,]}`), "json: invalid character ']' at offset 9"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		err := lzjson.Render(&buf, test.Node)
+		if err == nil {
+			t.Errorf("expected error, got nil")
+			continue
+		}
+		if want, have := test.Err, err.Error(); want != have {
+			t.Errorf("expected %#v, got %#v", want, have)
+		}
+		if want, have := "", buf.String(); want != have {
+			t.Errorf("expected %#v, got %#v", want, have)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package lzjson
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// syntaxError describes a malformed JSON input
+// found while scanning the raw bytes
+type syntaxError struct {
+	msg    string
+	offset int
+}
+
+// Error implements error type
+func (err syntaxError) Error() string {
+	return fmt.Sprintf("%s at offset %d", err.msg, err.offset)
+}
+
+// Is tells if the target is ErrorSyntax, for errors.Is
+func (err syntaxError) Is(target error) bool {
+	return target == ErrorSyntax
+}
+
+// fromJSONError converts *json.SyntaxError of encoding/json
+// into syntaxError, so it matches ErrorSyntax as well
+func fromJSONError(err error) error {
+	if serr, ok := err.(*json.SyntaxError); ok {
+		return syntaxError{serr.Error(), int(serr.Offset)}
+	}
+	return err
+}
+
+// objMember holds the offsets of a member in
+// a raw JSON object
+type objMember struct {
+	key      string
+	keyStart int // offset of the opening quote of the key
+	keyEnd   int // offset right after the closing quote of the key
+	valStart int
+	valEnd   int
+}
+
+// arrElem holds the offsets of an element in
+// a raw JSON array
+type arrElem struct {
+	start int
+	end   int
+}
+
+// isSpace tells if the byte is a JSON whitespace
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// skipSpace returns the offset of the first non-whitespace
+// byte in b at or after i
+func skipSpace(b []byte, i int) int {
+	for i < len(b) && isSpace(b[i]) {
+		i++
+	}
+	return i
+}
+
+// unexpected returns a syntaxError for the byte at offset i
+func unexpected(b []byte, i int) error {
+	if i >= len(b) {
+		return syntaxError{"unexpected end of JSON input", i}
+	}
+	return syntaxError{fmt.Sprintf("invalid character %q", b[i]), i}
+}
+
+// scanValue validates the JSON value starting at b[i]
+// and returns the offset right after it
+func scanValue(b []byte, i int) (end int, err error) {
+	if i >= len(b) {
+		return i, unexpected(b, i)
+	}
+	switch c := b[i]; {
+	case c == '{':
+		_, end, err = scanObject(b, i)
+	case c == '[':
+		_, end, err = scanArray(b, i)
+	case c == '"':
+		end, err = scanString(b, i)
+	case c == '-' || (c >= '0' && c <= '9'):
+		end, err = scanNumber(b, i)
+	default:
+		end, err = scanLiteral(b, i)
+	}
+	return
+}
+
+// scanLiteral scans true, false or null at b[i]
+func scanLiteral(b []byte, i int) (int, error) {
+	for _, lit := range []string{"true", "false", "null"} {
+		if len(b)-i >= len(lit) && string(b[i:i+len(lit)]) == lit {
+			return i + len(lit), nil
+		}
+	}
+	return i, unexpected(b, i)
+}
+
+// scanDigits returns the offset after a run of digits
+func scanDigits(b []byte, i int) int {
+	for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+		i++
+	}
+	return i
+}
+
+// scanNumber scans a JSON number at b[i]
+func scanNumber(b []byte, i int) (int, error) {
+	if b[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(b) && b[i] == '0':
+		i++
+	case i < len(b) && b[i] >= '1' && b[i] <= '9':
+		i = scanDigits(b, i)
+	default:
+		return i, unexpected(b, i)
+	}
+	if i < len(b) && b[i] == '.' {
+		j := scanDigits(b, i+1)
+		if j == i+1 {
+			return j, unexpected(b, j)
+		}
+		i = j
+	}
+	if i < len(b) && (b[i] == 'e' || b[i] == 'E') {
+		i++
+		if i < len(b) && (b[i] == '+' || b[i] == '-') {
+			i++
+		}
+		j := scanDigits(b, i)
+		if j == i {
+			return j, unexpected(b, j)
+		}
+		i = j
+	}
+	return i, nil
+}
+
+// scanString scans a JSON string at b[i] and returns
+// the offset right after the closing quote
+func scanString(b []byte, i int) (int, error) {
+	for i++; i < len(b); i++ {
+		switch c := b[i]; {
+		case c == '"':
+			return i + 1, nil
+		case c == '\\':
+			i++
+			if i >= len(b) {
+				return i, unexpected(b, i)
+			}
+			switch b[i] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				for j := 0; j < 4; j++ {
+					i++
+					if i >= len(b) || !isHex(b[i]) {
+						return i, unexpected(b, i)
+					}
+				}
+			default:
+				return i, unexpected(b, i)
+			}
+		case c < 0x20:
+			return i, unexpected(b, i)
+		}
+	}
+	return i, unexpected(b, i)
+}
+
+// isHex tells if the byte is a hexadecimal digit
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// unquote returns the string value of the raw JSON string
+func unquote(raw []byte) (str string) {
+	for _, c := range raw[1 : len(raw)-1] {
+		if c == '\\' {
+			json.Unmarshal(raw, &str)
+			return
+		}
+	}
+	return string(raw[1 : len(raw)-1])
+}
+
+// scanObject scans a JSON object at b[i] and returns
+// its members and the offset right after the closing brace
+func scanObject(b []byte, i int) (members []objMember, end int, err error) {
+	members = []objMember{}
+	i = skipSpace(b, i+1)
+	if i < len(b) && b[i] == '}' {
+		return members, i + 1, nil
+	}
+	for {
+		var m objMember
+		if i >= len(b) || b[i] != '"' {
+			return nil, i, unexpected(b, i)
+		}
+		m.keyStart = i
+		if m.keyEnd, err = scanString(b, i); err != nil {
+			return nil, m.keyEnd, err
+		}
+		m.key = unquote(b[m.keyStart:m.keyEnd])
+		i = skipSpace(b, m.keyEnd)
+		if i >= len(b) || b[i] != ':' {
+			return nil, i, unexpected(b, i)
+		}
+		m.valStart = skipSpace(b, i+1)
+		if m.valEnd, err = scanValue(b, m.valStart); err != nil {
+			return nil, m.valEnd, err
+		}
+		members = append(members, m)
+		i = skipSpace(b, m.valEnd)
+		if i < len(b) && b[i] == '}' {
+			return members, i + 1, nil
+		}
+		if i >= len(b) || b[i] != ',' {
+			return nil, i, unexpected(b, i)
+		}
+		i = skipSpace(b, i+1)
+	}
+}
+
+// scanArray scans a JSON array at b[i] and returns
+// its elements and the offset right after the closing bracket
+func scanArray(b []byte, i int) (elems []arrElem, end int, err error) {
+	elems = []arrElem{}
+	i = skipSpace(b, i+1)
+	if i < len(b) && b[i] == ']' {
+		return elems, i + 1, nil
+	}
+	for {
+		var e arrElem
+		e.start = i
+		if e.end, err = scanValue(b, i); err != nil {
+			return nil, e.end, err
+		}
+		elems = append(elems, e)
+		i = skipSpace(b, e.end)
+		if i < len(b) && b[i] == ']' {
+			return elems, i + 1, nil
+		}
+		if i >= len(b) || b[i] != ',' {
+			return nil, i, unexpected(b, i)
+		}
+		i = skipSpace(b, i+1)
+	}
+}
+
+// lastMember returns the index of the last member with
+// the given key (which is the effective one), or -1
+func lastMember(members []objMember, key string) int {
+	for i := len(members) - 1; i >= 0; i-- {
+		if members[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,113 @@
+package lzjson
+
+import "testing"
+
+func TestScanValue(t *testing.T) {
+	valid := []string{
+		`"hello"`,
+		`"esc \" \\ \/ \b \f \n \r \t é"`,
+		`0`,
+		`-1234.56789E+12`,
+		`true`,
+		`false`,
+		`null`,
+		`[]`,
+		`[ 1, "two", [3], {"four": 4} ]`,
+		`{}`,
+		`{ "a" : 1 , "b": {"c": [null]} }`,
+	}
+	for _, str := range valid {
+		b := []byte(str + "  ")
+		if end, err := scanValue(b, 0); err != nil {
+			t.Errorf("json=%#v unexpected error: %s", str, err.Error())
+		} else if want, have := len(str), end; want != have {
+			t.Errorf("json=%#v expected end %#v, got %#v", str, want, have)
+		}
+	}
+
+	invalid := []struct {
+		JSON string
+		Err  string
+	}{
+		{``, "unexpected end of JSON input at offset 0"},
+		{`"hello`, "unexpected end of JSON input at offset 6"},
+		{`"\x"`, `invalid character 'x' at offset 2`},
+		{`"\u12g4"`, `invalid character 'g' at offset 5`},
+		{`01`, ""}, // only the 0 is scanned
+		{`1.`, "unexpected end of JSON input at offset 2"},
+		{`1e+`, "unexpected end of JSON input at offset 3"},
+		{`-`, "unexpected end of JSON input at offset 1"},
+		{`tru`, `invalid character 't' at offset 0`},
+		{`[1, 2,]`, `invalid character ']' at offset 6`},
+		{`[1 2]`, `invalid character '2' at offset 3`},
+		{`{"a" 1}`, `invalid character '1' at offset 5`},
+		{`{"a": 1,}`, `invalid character '}' at offset 8`},
+		{`{a: 1}`, `invalid character 'a' at offset 1`},
+	}
+	for _, test := range invalid {
+		end, err := scanValue([]byte(test.JSON), 0)
+		if test.Err == "" {
+			if err != nil {
+				t.Errorf("json=%#v unexpected error: %s", test.JSON, err.Error())
+			} else if end == len(test.JSON) {
+				t.Errorf("json=%#v expected partial scan, got end=%d", test.JSON, end)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("json=%#v expected error, got nil", test.JSON)
+		} else if want, have := test.Err, err.Error(); want != have {
+			t.Errorf("json=%#v expected %#v, got %#v", test.JSON, want, have)
+		}
+	}
+}
+
+func TestScanObject(t *testing.T) {
+	b := []byte(`{"b": 1, "a b": [true], "b": "x"}`)
+	members, end, err := scanObject(b, 0)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+		return
+	}
+	if want, have := len(b), end; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := 3, len(members); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+		return
+	}
+	if want, have := "a b", members[1].key; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := "[true]", string(b[members[1].valStart:members[1].valEnd]); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := 2, lastMember(members, "b"); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	if want, have := -1, lastMember(members, "c"); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+}
+
+func TestScanArray(t *testing.T) {
+	b := []byte(`[ 1 , {"a": [2]},"three" ]`)
+	elems, end, err := scanArray(b, 0)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+		return
+	}
+	if want, have := len(b), end; want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+	}
+	expected := []string{`1`, `{"a": [2]}`, `"three"`}
+	if want, have := len(expected), len(elems); want != have {
+		t.Errorf("expected %#v, got %#v", want, have)
+		return
+	}
+	for i, want := range expected {
+		if have := string(b[elems[i].start:elems[i].end]); want != have {
+			t.Errorf("expected %#v, got %#v", want, have)
+		}
+	}
+}
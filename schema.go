@@ -0,0 +1,750 @@
+package lzjson
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaError describes a violation of a JSON Schema keyword
+type SchemaError struct {
+	// KeywordLocation is the JSON Pointer to the failing keyword,
+	// following the evaluation path from the schema root
+	// (e.g. "/properties/age/minimum", "/$ref/type")
+	KeywordLocation string
+	Message         string
+}
+
+// Error implements error type
+func (err SchemaError) Error() string {
+	return err.Message + " (" + err.KeywordLocation + ")"
+}
+
+// Schema is a compiled JSON Schema, ready for validation
+//
+// Draft 2020-12 is supported with the following limits:
+//  1. $ref can only refer to the same document (e.g. "#/$defs/foo",
+//     "#anchor" or the document's own $id), and a $ref back to the
+//     same value without consuming it (e.g. {"$ref": "#"}) is
+//     refused by CompileSchema;
+//  2. $dynamicRef, unevaluatedItems and unevaluatedProperties are not
+//     supported; and
+//  3. format and other annotations are ignored.
+type Schema struct {
+	root *schemaNode
+}
+
+// schemaNode is a compiled schema or subschema
+type schemaNode struct {
+	always *bool // for boolean schema
+
+	ref     string
+	refTo   *schemaNode
+	types   []string
+	enum    [][]byte
+	hasEnum bool
+	konst   []byte
+
+	multipleOf       *big.Rat
+	maximum          *big.Rat
+	exclusiveMaximum *big.Rat
+	minimum          *big.Rat
+	exclusiveMinimum *big.Rat
+
+	maxLength     int
+	minLength     int
+	pattern       *regexp.Regexp
+	maxItems      int
+	minItems      int
+	uniqueItems   bool
+	maxProperties int
+	minProperties int
+	maxContains   int
+	minContains   int
+
+	required          []string
+	dependentRequired map[string][]string
+
+	properties           map[string]*schemaNode
+	patternProperties    []patternSchema
+	additionalProperties *schemaNode
+	propertyNames        *schemaNode
+	dependentSchemas     map[string]*schemaNode
+
+	prefixItems []*schemaNode
+	items       *schemaNode
+	contains    *schemaNode
+
+	allOf []*schemaNode
+	anyOf []*schemaNode
+	oneOf []*schemaNode
+	not   *schemaNode
+	ifS   *schemaNode
+	thenS *schemaNode
+	elseS *schemaNode
+}
+
+// patternSchema is a compiled patternProperties member
+type patternSchema struct {
+	key    string
+	re     *regexp.Regexp
+	schema *schemaNode
+}
+
+// schemaCompiler holds the states of compiling a schema document
+type schemaCompiler struct {
+	id         string
+	byLocation map[string]*schemaNode
+	anchors    map[string]*schemaNode
+	refs       []*schemaNode
+}
+
+// schemaStrings returns the strings in the validated raw JSON
+// array, or ok = false if it is not an array of strings
+func schemaStrings(b []byte) (list []string, ok bool) {
+	if b[0] != '[' {
+		return nil, false
+	}
+	elems, _, _ := scanArray(b, 0)
+	list = make([]string, len(elems))
+	for i, e := range elems {
+		if b[e.start] != '"' {
+			return nil, false
+		}
+		list[i] = unquote(b[e.start:e.end])
+	}
+	return list, true
+}
+
+// escapePointer escapes a JSON Pointer token
+func escapePointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+// CompileSchema compiles a JSON Schema document for validation
+func CompileSchema(schema Node) (*Schema, error) {
+	b, err := rawValue(schema)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, Error{Path: "json" + nodePath(schema), Err: ErrorUndefined}
+	}
+	c := &schemaCompiler{
+		byLocation: map[string]*schemaNode{},
+		anchors:    map[string]*schemaNode{},
+	}
+	if b[0] == '{' {
+		members, _, _ := scanObject(b, 0)
+		if i := lastMember(members, "$id"); i >= 0 {
+			c.id = strings.TrimSuffix(unquote(b[members[i].valStart:members[i].valEnd]), "#")
+		}
+	}
+	root, err := c.compile(b, "", nodePath(schema))
+	if err != nil {
+		return nil, err
+	}
+
+	// resolve references
+	for _, n := range c.refs {
+		if n.refTo, err = c.resolve(n.ref); err != nil {
+			return nil, err
+		}
+	}
+	state := map[*schemaNode]int{}
+	for _, n := range c.refs {
+		if err := checkCycle(n, state, nil); err != nil {
+			return nil, err
+		}
+	}
+	return &Schema{root: root}, nil
+}
+
+// inPlace returns the subschemas applied to the same
+// value as the schema node, i.e. without consuming any
+// part of the value
+func (n *schemaNode) inPlace() []*schemaNode {
+	list := []*schemaNode{n.refTo, n.not, n.ifS, n.thenS, n.elseS}
+	list = append(list, n.allOf...)
+	list = append(list, n.anyOf...)
+	list = append(list, n.oneOf...)
+	for _, sub := range n.dependentSchemas {
+		list = append(list, sub)
+	}
+	return list
+}
+
+// checkCycle returns an error if the schema node can reach
+// itself through subschemas applied in place. Validating such
+// a schema would never end. The state of a node is 1 while it
+// is on the stack, and 2 once checked
+func checkCycle(n *schemaNode, state map[*schemaNode]int, stack []*schemaNode) error {
+	switch state[n] {
+	case 1:
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].ref != "" {
+				return fmt.Errorf("cyclic $ref %#v: the schema refers to itself without consuming the value", stack[i].ref)
+			}
+		}
+	case 2:
+		return nil
+	}
+	state[n] = 1
+	stack = append(stack, n)
+	for _, sub := range n.inPlace() {
+		if sub == nil {
+			continue
+		}
+		if err := checkCycle(sub, state, stack); err != nil {
+			return err
+		}
+	}
+	state[n] = 2
+	return nil
+}
+
+// resolve finds the schema node of the local reference
+func (c *schemaCompiler) resolve(ref string) (*schemaNode, error) {
+	i := strings.IndexByte(ref, '#')
+	if i < 0 {
+		i = len(ref)
+	}
+	if base := ref[:i]; base != "" && base != c.id {
+		return nil, fmt.Errorf("unsupported $ref %#v: only local references are supported", ref)
+	}
+	fragment := ""
+	if i < len(ref) {
+		var err error
+		if fragment, err = url.PathUnescape(ref[i+1:]); err != nil {
+			return nil, fmt.Errorf("invalid $ref %#v: %s", ref, err.Error())
+		}
+	}
+	var n *schemaNode
+	if fragment == "" || fragment[0] == '/' {
+		n = c.byLocation[fragment]
+	} else {
+		n = c.anchors[fragment]
+	}
+	if n == nil {
+		return nil, fmt.Errorf("unresolvable $ref %#v", ref)
+	}
+	return n, nil
+}
+
+// compile compiles the validated raw JSON schema at the location
+// (a JSON Pointer) and path (in Error path format)
+func (c *schemaCompiler) compile(b []byte, loc, path string) (n *schemaNode, err error) {
+	n = &schemaNode{
+		maxLength:     -1,
+		maxItems:      -1,
+		maxProperties: -1,
+		maxContains:   -1,
+		minContains:   1,
+	}
+	c.byLocation[loc] = n
+
+	switch string(b) {
+	case "true", "false":
+		always := string(b) == "true"
+		n.always = &always
+		return
+	}
+	if b[0] != '{' {
+		return nil, Error{Path: "json" + path, Err: fmt.Errorf("schema must be an object or boolean")}
+	}
+
+	members, _, _ := scanObject(b, 0)
+	for _, m := range members {
+		v := b[m.valStart:m.valEnd]
+		kloc, kpath := loc+"/"+escapePointer(m.key), keyPath(path, m.key)
+		invalid := func(format string, args ...interface{}) error {
+			return Error{Path: "json" + kpath, Err: fmt.Errorf(format, args...)}
+		}
+
+		// helpers to compile the different forms of keyword values
+		sub := func() (*schemaNode, error) {
+			return c.compile(v, kloc, kpath)
+		}
+		subArray := func() (list []*schemaNode, err error) {
+			if v[0] != '[' {
+				return nil, invalid("expected an array of schemas")
+			}
+			elems, _, _ := scanArray(v, 0)
+			list = make([]*schemaNode, len(elems))
+			for i, e := range elems {
+				if list[i], err = c.compile(v[e.start:e.end], kloc+"/"+strconv.Itoa(i), nthPath(kpath, i)); err != nil {
+					return nil, err
+				}
+			}
+			return
+		}
+		subMap := func() (subs map[string]*schemaNode, err error) {
+			if v[0] != '{' {
+				return nil, invalid("expected an object of schemas")
+			}
+			subs = map[string]*schemaNode{}
+			pm, _, _ := scanObject(v, 0)
+			for _, p := range pm {
+				if subs[p.key], err = c.compile(v[p.valStart:p.valEnd], kloc+"/"+escapePointer(p.key), keyPath(kpath, p.key)); err != nil {
+					return nil, err
+				}
+			}
+			return
+		}
+		number := func() (*big.Rat, error) {
+			r, ok := new(big.Rat).SetString(string(v))
+			if !isNumStart(v[0]) || !ok {
+				return nil, invalid("expected a number")
+			}
+			return r, nil
+		}
+		count := func() (int, error) {
+			if !isNumStart(v[0]) || !isInteger(v) || v[0] == '-' {
+				return 0, invalid("expected a non-negative integer")
+			}
+			f, _ := strconv.ParseFloat(string(v), 64)
+			return int(f), nil
+		}
+		stringList := func() ([]string, error) {
+			list, ok := schemaStrings(v)
+			if !ok {
+				return nil, invalid("expected an array of strings")
+			}
+			return list, nil
+		}
+
+		switch m.key {
+		case "$ref":
+			if v[0] != '"' {
+				return nil, invalid("expected a string")
+			}
+			n.ref = unquote(v)
+			c.refs = append(c.refs, n)
+		case "$anchor":
+			if v[0] != '"' {
+				return nil, invalid("expected a string")
+			}
+			c.anchors[unquote(v)] = n
+		case "$defs", "definitions":
+			_, err = subMap()
+		case "type":
+			if v[0] == '"' {
+				n.types = []string{unquote(v)}
+			} else {
+				n.types, err = stringList()
+			}
+		case "enum":
+			if v[0] != '[' {
+				return nil, invalid("expected an array")
+			}
+			elems, _, _ := scanArray(v, 0)
+			n.hasEnum = true
+			n.enum = make([][]byte, len(elems))
+			for i, e := range elems {
+				n.enum[i] = v[e.start:e.end]
+			}
+		case "const":
+			n.konst = v
+		case "multipleOf":
+			n.multipleOf, err = number()
+			if err == nil && n.multipleOf.Sign() <= 0 {
+				err = invalid("expected a number larger than 0")
+			}
+		case "maximum":
+			n.maximum, err = number()
+		case "exclusiveMaximum":
+			n.exclusiveMaximum, err = number()
+		case "minimum":
+			n.minimum, err = number()
+		case "exclusiveMinimum":
+			n.exclusiveMinimum, err = number()
+		case "maxLength":
+			n.maxLength, err = count()
+		case "minLength":
+			n.minLength, err = count()
+		case "pattern":
+			if v[0] != '"' {
+				return nil, invalid("expected a string")
+			}
+			if n.pattern, err = regexp.Compile(unquote(v)); err != nil {
+				err = invalid("invalid pattern: %s", err.Error())
+			}
+		case "maxItems":
+			n.maxItems, err = count()
+		case "minItems":
+			n.minItems, err = count()
+		case "uniqueItems":
+			n.uniqueItems = string(v) == "true"
+		case "maxContains":
+			n.maxContains, err = count()
+		case "minContains":
+			n.minContains, err = count()
+		case "maxProperties":
+			n.maxProperties, err = count()
+		case "minProperties":
+			n.minProperties, err = count()
+		case "required":
+			n.required, err = stringList()
+		case "dependentRequired":
+			if v[0] != '{' {
+				return nil, invalid("expected an object")
+			}
+			n.dependentRequired = map[string][]string{}
+			dm, _, _ := scanObject(v, 0)
+			for _, d := range dm {
+				list, ok := schemaStrings(v[d.valStart:d.valEnd])
+				if !ok {
+					return nil, Error{Path: "json" + keyPath(kpath, d.key), Err: fmt.Errorf("expected an array of strings")}
+				}
+				n.dependentRequired[d.key] = list
+			}
+		case "properties":
+			n.properties, err = subMap()
+		case "patternProperties":
+			var subs map[string]*schemaNode
+			if subs, err = subMap(); err != nil {
+				return
+			}
+			pm, _, _ := scanObject(v, 0)
+			for _, p := range pm {
+				re, rerr := regexp.Compile(p.key)
+				if rerr != nil {
+					return nil, invalid("invalid pattern %#v: %s", p.key, rerr.Error())
+				}
+				n.patternProperties = append(n.patternProperties, patternSchema{key: p.key, re: re, schema: subs[p.key]})
+			}
+		case "additionalProperties":
+			n.additionalProperties, err = sub()
+		case "propertyNames":
+			n.propertyNames, err = sub()
+		case "dependentSchemas":
+			n.dependentSchemas, err = subMap()
+		case "prefixItems":
+			n.prefixItems, err = subArray()
+		case "items":
+			n.items, err = sub()
+		case "contains":
+			n.contains, err = sub()
+		case "allOf":
+			n.allOf, err = subArray()
+		case "anyOf":
+			n.anyOf, err = subArray()
+		case "oneOf":
+			n.oneOf, err = subArray()
+		case "not":
+			n.not, err = sub()
+		case "if":
+			n.ifS, err = sub()
+		case "then":
+			n.thenS, err = sub()
+		case "else":
+			n.elseS, err = sub()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// Validate validates the node against the schema. It returns nil
+// if the node is valid, or Errors with every violation found. The Path
+// of each Error is the location of the invalid value in the node, and
+// its Err is a SchemaError with the location of the failing keyword.
+//
+// Validation works directly on the raw JSON. Only the values
+// that the schema has keywords for are visited, and no value
+// is unmarshaled.
+func (s *Schema) Validate(n Node) error {
+	b, err := rawValue(n)
+	if err != nil {
+		if lerr, ok := err.(Error); ok {
+			return Errors{lerr}
+		}
+		return Errors{{Path: "json" + nodePath(n), Err: err}}
+	}
+	if len(b) == 0 {
+		return Errors{{Path: "json" + nodePath(n), Err: ErrorUndefined}}
+	}
+	errs := Errors{}
+	s.root.validate(b, nodePath(n), "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// valid tells if the value is valid against the schema node
+func (n *schemaNode) valid(b []byte, path, kw string) bool {
+	errs := Errors{}
+	n.validate(b, path, kw, &errs)
+	return len(errs) == 0
+}
+
+// matchType tells if the validated raw JSON value is of the JSON Schema type
+func matchType(b []byte, typ string) bool {
+	switch t := typeName(b); {
+	case typ == t:
+		return true
+	case typ == "boolean":
+		return t == "bool"
+	case typ == "integer":
+		return t == "number" && isInteger(b)
+	}
+	return false
+}
+
+// validate appends every violation of the validated raw JSON value
+// at path, evaluated at the keyword location kw, to errs
+func (n *schemaNode) validate(b []byte, path, kw string, errs *Errors) {
+	fail := func(keyword, format string, args ...interface{}) {
+		*errs = append(*errs, Error{
+			Path: "json" + path,
+			Err: SchemaError{
+				KeywordLocation: kw + "/" + keyword,
+				Message:         fmt.Sprintf(format, args...),
+			},
+		})
+	}
+
+	if n.always != nil {
+		if !*n.always {
+			*errs = append(*errs, Error{
+				Path: "json" + path,
+				Err:  SchemaError{KeywordLocation: kw, Message: "not allowed"},
+			})
+		}
+		return
+	}
+
+	if n.refTo != nil {
+		n.refTo.validate(b, path, kw+"/$ref", errs)
+	}
+
+	if len(n.types) > 0 {
+		matched := false
+		for _, typ := range n.types {
+			matched = matched || matchType(b, typ)
+		}
+		if !matched {
+			fail("type", "expected %s, got %s", strings.Join(n.types, " or "), typeName(b))
+		}
+	}
+	if n.hasEnum {
+		matched := false
+		for _, e := range n.enum {
+			matched = matched || jsonEqual(b, e)
+		}
+		if !matched {
+			fail("enum", "value %s is not one of the enum values", compactRaw(b))
+		}
+	}
+	if n.konst != nil && !jsonEqual(b, n.konst) {
+		fail("const", "expected %s, got %s", compactRaw(n.konst), compactRaw(b))
+	}
+
+	switch {
+	case isNumStart(b[0]):
+		n.validateNumber(b, fail)
+	case b[0] == '"':
+		n.validateString(b, fail)
+	case b[0] == '[':
+		n.validateArray(b, path, kw, fail, errs)
+	case b[0] == '{':
+		n.validateObject(b, path, kw, fail, errs)
+	}
+
+	// logic applicators
+	for i, s := range n.allOf {
+		s.validate(b, path, kw+"/allOf/"+strconv.Itoa(i), errs)
+	}
+	if len(n.anyOf) > 0 {
+		matched := false
+		for i, s := range n.anyOf {
+			if s.valid(b, path, kw+"/anyOf/"+strconv.Itoa(i)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("anyOf", "value does not match any of the schemas")
+		}
+	}
+	if len(n.oneOf) > 0 {
+		count := 0
+		for i, s := range n.oneOf {
+			if s.valid(b, path, kw+"/oneOf/"+strconv.Itoa(i)) {
+				count++
+			}
+		}
+		if count != 1 {
+			fail("oneOf", "value matches %d of the schemas, expected exactly 1", count)
+		}
+	}
+	if n.not != nil && n.not.valid(b, path, kw+"/not") {
+		fail("not", "value should not match the schema")
+	}
+	if n.ifS != nil {
+		if n.ifS.valid(b, path, kw+"/if") {
+			if n.thenS != nil {
+				n.thenS.validate(b, path, kw+"/then", errs)
+			}
+		} else if n.elseS != nil {
+			n.elseS.validate(b, path, kw+"/else", errs)
+		}
+	}
+}
+
+// validateNumber validates the number keywords
+func (n *schemaNode) validateNumber(b []byte, fail func(string, string, ...interface{})) {
+	v, ok := new(big.Rat).SetString(string(b))
+	if !ok {
+		return
+	}
+	if n.multipleOf != nil && !new(big.Rat).Quo(v, n.multipleOf).IsInt() {
+		fail("multipleOf", "%s is not a multiple of %s", b, n.multipleOf.RatString())
+	}
+	if n.maximum != nil && v.Cmp(n.maximum) > 0 {
+		fail("maximum", "%s is larger than %s", b, n.maximum.RatString())
+	}
+	if n.exclusiveMaximum != nil && v.Cmp(n.exclusiveMaximum) >= 0 {
+		fail("exclusiveMaximum", "%s is not smaller than %s", b, n.exclusiveMaximum.RatString())
+	}
+	if n.minimum != nil && v.Cmp(n.minimum) < 0 {
+		fail("minimum", "%s is smaller than %s", b, n.minimum.RatString())
+	}
+	if n.exclusiveMinimum != nil && v.Cmp(n.exclusiveMinimum) <= 0 {
+		fail("exclusiveMinimum", "%s is not larger than %s", b, n.exclusiveMinimum.RatString())
+	}
+}
+
+// validateString validates the string keywords
+func (n *schemaNode) validateString(b []byte, fail func(string, string, ...interface{})) {
+	if n.maxLength < 0 && n.minLength == 0 && n.pattern == nil {
+		return // no need to unquote
+	}
+	str := unquote(b)
+	l := utf8.RuneCountInString(str)
+	if n.maxLength >= 0 && l > n.maxLength {
+		fail("maxLength", "length %d is longer than %d", l, n.maxLength)
+	}
+	if l < n.minLength {
+		fail("minLength", "length %d is shorter than %d", l, n.minLength)
+	}
+	if n.pattern != nil && !n.pattern.MatchString(str) {
+		fail("pattern", "%#v does not match /%s/", str, n.pattern.String())
+	}
+}
+
+// validateArray validates the array keywords and applicators
+func (n *schemaNode) validateArray(b []byte, path, kw string, fail func(string, string, ...interface{}), errs *Errors) {
+	elems, _, _ := scanArray(b, 0)
+	item := func(i int) []byte {
+		return b[elems[i].start:elems[i].end]
+	}
+
+	if n.maxItems >= 0 && len(elems) > n.maxItems {
+		fail("maxItems", "%d items is more than %d", len(elems), n.maxItems)
+	}
+	if len(elems) < n.minItems {
+		fail("minItems", "%d items is less than %d", len(elems), n.minItems)
+	}
+	if n.uniqueItems {
+		seen := map[uint64][]int{}
+		for i := range elems {
+			h := Hash(Parse(item(i)), EqualIgnoreKeyOrder())
+			for _, j := range seen[h] {
+				if jsonEqual(item(i), item(j)) {
+					fail("uniqueItems", "items %d and %d are equal", j, i)
+				}
+			}
+			seen[h] = append(seen[h], i)
+		}
+	}
+	for i, s := range n.prefixItems {
+		if i < len(elems) {
+			s.validate(item(i), nthPath(path, i), kw+"/prefixItems/"+strconv.Itoa(i), errs)
+		}
+	}
+	if n.items != nil {
+		for i := len(n.prefixItems); i < len(elems); i++ {
+			n.items.validate(item(i), nthPath(path, i), kw+"/items", errs)
+		}
+	}
+	if n.contains != nil {
+		count := 0
+		for i := range elems {
+			if n.contains.valid(item(i), nthPath(path, i), kw+"/contains") {
+				count++
+			}
+		}
+		if count < n.minContains {
+			fail("minContains", "%d items match contains, expected at least %d", count, n.minContains)
+		}
+		if n.maxContains >= 0 && count > n.maxContains {
+			fail("maxContains", "%d items match contains, expected at most %d", count, n.maxContains)
+		}
+	}
+}
+
+// validateObject validates the object keywords and applicators
+func (n *schemaNode) validateObject(b []byte, path, kw string, fail func(string, string, ...interface{}), errs *Errors) {
+	all, _, _ := scanObject(b, 0)
+	members := make([]objMember, 0, len(all))
+	for i, m := range all {
+		if lastMember(all, m.key) == i {
+			members = append(members, m)
+		}
+	}
+	has := func(key string) bool {
+		return lastMember(members, key) >= 0
+	}
+
+	if n.maxProperties >= 0 && len(members) > n.maxProperties {
+		fail("maxProperties", "%d properties is more than %d", len(members), n.maxProperties)
+	}
+	if len(members) < n.minProperties {
+		fail("minProperties", "%d properties is less than %d", len(members), n.minProperties)
+	}
+	for _, key := range n.required {
+		if !has(key) {
+			fail("required", "missing required property %#v", key)
+		}
+	}
+	for _, m := range members {
+		for _, dep := range n.dependentRequired[m.key] {
+			if !has(dep) {
+				fail("dependentRequired/"+escapePointer(m.key), "property %#v is required by %#v", dep, m.key)
+			}
+		}
+		if s, ok := n.dependentSchemas[m.key]; ok {
+			s.validate(b, path, kw+"/dependentSchemas/"+escapePointer(m.key), errs)
+		}
+	}
+
+	for _, m := range members {
+		v, p := b[m.valStart:m.valEnd], keyPath(path, m.key)
+		if n.propertyNames != nil {
+			key, _ := FromValue(m.key)
+			n.propertyNames.validate(key.Raw(), p, kw+"/propertyNames", errs)
+		}
+		evaluated := false
+		if s, ok := n.properties[m.key]; ok {
+			s.validate(v, p, kw+"/properties/"+escapePointer(m.key), errs)
+			evaluated = true
+		}
+		for _, ps := range n.patternProperties {
+			if ps.re.MatchString(m.key) {
+				ps.schema.validate(v, p, kw+"/patternProperties/"+escapePointer(ps.key), errs)
+				evaluated = true
+			}
+		}
+		if !evaluated && n.additionalProperties != nil {
+			n.additionalProperties.validate(v, p, kw+"/additionalProperties", errs)
+		}
+	}
+}
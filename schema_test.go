@@ -0,0 +1,201 @@
+package lzjson_test
+
+import (
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+func mustCompileSchema(t *testing.T, str string) *lzjson.Schema {
+	s, err := lzjson.CompileSchema(lzjson.ParseString(str))
+	if err != nil {
+		t.Fatalf("unexpected error compiling schema: %s", err.Error())
+	}
+	return s
+}
+
+func assertSchemaErrors(t *testing.T, name string, expected []string, err error) {
+	if len(expected) == 0 {
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err.Error())
+		}
+		return
+	}
+	errs, ok := err.(lzjson.Errors)
+	if !ok {
+		t.Errorf("%s: expected lzjson.Errors, got %#v", name, err)
+		return
+	}
+	if want, have := len(expected), len(errs); want != have {
+		t.Errorf("%s: expected %d errors, got %d:\n%s", name, want, have, errs.Error())
+		return
+	}
+	for i := range expected {
+		if want, have := expected[i], errs[i].Error(); want != have {
+			t.Errorf("%s: errs[%d]\nexpected: %s\ngot:      %s", name, i, want, have)
+		}
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	s := mustCompileSchema(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"required": ["id", "name"],
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"name": {"type": "string", "minLength": 1, "maxLength": 5, "pattern": "^\\p{Lu}"},
+			"price": {"type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01},
+			"tags": {
+				"type": "array",
+				"items": {"$ref": "#/$defs/tag"},
+				"uniqueItems": true,
+				"maxItems": 3
+			},
+			"status": {"enum": ["active", "inactive"]},
+			"kind": {"const": "product"},
+			"dimensions": {
+				"type": "array",
+				"prefixItems": [{"type": "number"}, {"type": "number"}],
+				"items": false
+			}
+		},
+		"patternProperties": {"^x-": true},
+		"additionalProperties": false,
+		"$defs": {
+			"tag": {"type": "string", "minLength": 2}
+		}
+	}`)
+
+	tests := []struct {
+		JSON string
+		Errs []string
+	}{
+		{`{"id": 1, "name": "Apple", "price": 1.25, "tags": ["ab", "cd"], "status": "active", "kind": "product", "dimensions": [1, 2.5], "x-extra": {}}`, nil},
+		{`{"id": 1.0, "name": "Éclair"}`, []string{"json.name: length 6 is longer than 5 (/properties/name/maxLength)"}},
+		{`{"name": "apple"}`, []string{
+			`json: missing required property "id" (/required)`,
+			`json.name: "apple" does not match /^\p{Lu}/ (/properties/name/pattern)`,
+		}},
+		{`{"id": 0.5, "name": "A", "price": 1.255}`, []string{
+			"json.id: expected integer, got number (/properties/id/type)",
+			"json.id: 0.5 is smaller than 1 (/properties/id/minimum)",
+			"json.price: 1.255 is not a multiple of 1/100 (/properties/price/multipleOf)",
+		}},
+		{`{"id": 1, "name": "A", "price": 0}`, []string{"json.price: 0 is not larger than 0 (/properties/price/exclusiveMinimum)"}},
+		{`{"id": 1, "name": "A", "tags": ["ab", "a", {"b": 1}, "ab"]}`, []string{
+			"json.tags: 4 items is more than 3 (/properties/tags/maxItems)",
+			"json.tags: items 0 and 3 are equal (/properties/tags/uniqueItems)",
+			"json.tags[1]: length 1 is shorter than 2 (/properties/tags/items/$ref/minLength)",
+			"json.tags[2]: expected string, got object (/properties/tags/items/$ref/type)",
+		}},
+		{`{"id": 1, "name": "A", "status": "deleted", "kind": "service"}`, []string{
+			`json.status: value "deleted" is not one of the enum values (/properties/status/enum)`,
+			`json.kind: expected "product", got "service" (/properties/kind/const)`,
+		}},
+		{`{"id": 1, "name": "A", "dimensions": [1, "2", 3]}`, []string{
+			"json.dimensions[1]: expected number, got string (/properties/dimensions/prefixItems/1/type)",
+			"json.dimensions[2]: not allowed (/properties/dimensions/items)",
+		}},
+		{`{"id": 1, "name": "A", "other": 1}`, []string{"json.other: not allowed (/additionalProperties)"}},
+		{`[]`, []string{`json: expected object, got array (/type)`}},
+	}
+	for _, test := range tests {
+		assertSchemaErrors(t, test.JSON, test.Errs, s.Validate(lzjson.ParseString(test.JSON)))
+	}
+}
+
+func TestSchema_Validate_applicators(t *testing.T) {
+	s := mustCompileSchema(t, `{
+		"$id": "https://example.com/shape",
+		"oneOf": [
+			{"$ref": "#circle"},
+			{"$ref": "https://example.com/shape#/$defs/rect"}
+		],
+		"not": {"required": ["deleted"]},
+		"if": {"properties": {"kind": {"const": "big"}}, "required": ["kind"]},
+		"then": {"properties": {"size": {"minimum": 100}}},
+		"else": {"properties": {"size": {"maximum": 10}}},
+		"dependentRequired": {"color": ["opacity"]},
+		"dependentSchemas": {"label": {"required": ["font"]}},
+		"propertyNames": {"maxLength": 7},
+		"$defs": {
+			"circle": {"$anchor": "circle", "required": ["radius"]},
+			"rect": {"required": ["width"], "anyOf": [{"required": ["height"]}, {"required": ["ratio"]}]}
+		}
+	}`)
+
+	tests := []struct {
+		JSON string
+		Errs []string
+	}{
+		{`{"radius": 1, "size": 5}`, nil},
+		{`{"width": 1, "ratio": 2, "kind": "big", "size": 200}`, nil},
+		{`{"radius": 1, "width": 1, "height": 1}`, []string{"json: value matches 2 of the schemas, expected exactly 1 (/oneOf)"}},
+		{`{"width": 1}`, []string{"json: value matches 0 of the schemas, expected exactly 1 (/oneOf)"}},
+		{`{"radius": 1, "deleted": true}`, []string{"json: value should not match the schema (/not)"}},
+		{`{"radius": 1, "kind": "big", "size": 5}`, []string{"json.size: 5 is smaller than 100 (/then/properties/size/minimum)"}},
+		{`{"radius": 1, "size": 50}`, []string{"json.size: 50 is larger than 10 (/else/properties/size/maximum)"}},
+		{`{"radius": 1, "color": "red", "label": "x"}`, []string{
+			`json: property "opacity" is required by "color" (/dependentRequired/color)`,
+			`json: missing required property "font" (/dependentSchemas/label/required)`,
+		}},
+		{`{"radius": 1, "tooLongName": 1}`, []string{"json.tooLongName: length 11 is longer than 7 (/propertyNames/maxLength)"}},
+	}
+	for _, test := range tests {
+		assertSchemaErrors(t, test.JSON, test.Errs, s.Validate(lzjson.ParseString(test.JSON)))
+	}
+}
+
+func TestSchema_Validate_recursive(t *testing.T) {
+	s := mustCompileSchema(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"children": {"type": "array", "items": {"$ref": "#"}, "contains": {"required": ["name"]}, "maxContains": 2}
+		}
+	}`)
+	assertSchemaErrors(t, "min contains", []string{
+		"json.children: 0 items match contains, expected at least 1 (/properties/children/minContains)",
+	}, s.Validate(lzjson.ParseString(`{"children": [{}]}`)))
+	assertSchemaErrors(t, "valid", nil, s.Validate(lzjson.ParseString(`{"children": [{"name": "a", "children": [{"name": "b"}]}]}`)))
+	assertSchemaErrors(t, "invalid", []string{
+		"json.children[0].children[0].name: expected string, got number (/properties/children/items/$ref/properties/children/items/$ref/properties/name/type)",
+		"json.children: 3 items match contains, expected at most 2 (/properties/children/maxContains)",
+	}, s.Validate(lzjson.ParseString(`{"children": [{"name": "a", "children": [{"name": 1}]}, {"name": "b"}, {"name": "c"}]}`)))
+
+	// errors follow the path of the validated node
+	root := lzjson.ParseString(`{"data": {"name": 1}}`)
+	assertSchemaErrors(t, "child node", []string{
+		"json.data.name: expected string, got number (/properties/name/type)",
+	}, s.Validate(root.Get("data")))
+	assertSchemaErrors(t, "undefined node", []string{"json.meta: undefined"}, s.Validate(root.Get("meta")))
+}
+
+func TestCompileSchema_error(t *testing.T) {
+	tests := []struct {
+		Schema string
+		Err    string
+	}{
+		{`1`, "json: schema must be an object or boolean"},
+		{`{"properties": {"a": 1}}`, "json.properties.a: schema must be an object or boolean"},
+		{`{"minLength": -1}`, "json.minLength: expected a non-negative integer"},
+		{`{"pattern": "("}`, "json.pattern: invalid pattern: error parsing regexp: missing closing ): `(`"},
+		{`{"allOf": {}}`, "json.allOf: expected an array of schemas"},
+		{`{"required": [1]}`, "json.required: expected an array of strings"},
+		{`{"$ref": "#/$defs/none"}`, `unresolvable $ref "#/$defs/none"`},
+		{`{"$ref": "other.json#/foo"}`, `unsupported $ref "other.json#/foo": only local references are supported`},
+		{`{"$ref": "#"}`, `cyclic $ref "#": the schema refers to itself without consuming the value`},
+		{`{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"allOf": [{"$ref": "#/$defs/a"}]}}, "$ref": "#/$defs/a"}`,
+			`cyclic $ref "#/$defs/a": the schema refers to itself without consuming the value`},
+		{`{"anyOf": [{"type": "string"}, {"not": {"$ref": "#"}}]}`, `cyclic $ref "#": the schema refers to itself without consuming the value`},
+	}
+	for _, test := range tests {
+		_, err := lzjson.CompileSchema(lzjson.ParseString(test.Schema))
+		if err == nil {
+			t.Errorf("schema=%s expected error, got nil", test.Schema)
+		} else if want, have := test.Err, err.Error(); want != have {
+			t.Errorf("schema=%s\nexpected: %s\ngot:      %s", test.Schema, want, have)
+		}
+	}
+}
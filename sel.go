@@ -2,6 +2,7 @@ package lzjson
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 )
@@ -27,11 +28,9 @@ const (
 	selItemEnd                          // end of selector string
 )
 
-const (
-	charCap      = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	charSmallCap = "abcdefghijklmnoperstuvwxyz"
-	charNumeric  = "0123456789"
-)
+// charPropStop contains characters that end
+// a property name in selector
+const charPropStop = ".[] "
 
 const eof = -1
 
@@ -156,9 +155,14 @@ func selLexText(l *selLexer) selStateFn {
 }
 
 // selLexProc process selector string like object property
-// name until reaching non-alphanumerical character
+// name until reaching a dot, bracket, space or the end
 func selLexProc(l *selLexer) selStateFn {
-	l.acceptRun(charCap + charSmallCap + charNumeric)
+	for r := l.next(); r != eof; r = l.next() {
+		if strings.IndexRune(charPropStop, r) >= 0 {
+			l.backup()
+			break
+		}
+	}
 	l.emit(selItemProp)
 	return selLexText
 }
@@ -188,14 +192,13 @@ func selLexInsideQuoteString(l *selLexer) selStateFn {
 	for {
 		switch l.next() {
 		case '\\':
-			if l.peek() == '\'' {
+			// skip the escaped character
+			if l.peek() != eof {
 				l.next()
 			}
 		case '\'':
 			l.backup()
-			if l.pos > l.start {
-				l.emit(selItemString)
-			}
+			l.emit(selItemString)
 			l.next()
 			l.ignore()
 			return selLexText
@@ -209,19 +212,101 @@ func selLexInsideDoubleQuoteString(l *selLexer) selStateFn {
 	for {
 		switch l.next() {
 		case '\\':
-			if l.peek() == '"' {
+			// skip the escaped character
+			if l.peek() != eof {
 				l.next()
 			}
 		case '"':
 			l.backup()
-			if l.pos > l.start {
-				l.emit(selItemString)
-			}
+			l.emit(selItemString)
 			l.next()
 			l.ignore()
 			return selLexText
 		case eof:
-			return l.errorf("unclosed single quoted string")
+			return l.errorf("unclosed double quoted string")
+		}
+	}
+}
+
+// selStep is a parsed step of a selector. It is
+// either an object key or an array index
+type selStep struct {
+	key     string
+	nth     int
+	isIndex bool
+}
+
+// path returns the step formatted like the path
+// in Error, relative to the given parent path
+func (step selStep) path(parent string) string {
+	if step.isIndex {
+		return nthPath(parent, step.nth)
+	}
+	return keyPath(parent, step.key)
+}
+
+// unescapeSel removes the backslash escapes from a
+// quoted string in selector
+func unescapeSel(str string) string {
+	if strings.IndexRune(str, '\\') < 0 {
+		return str
+	}
+	b := make([]byte, 0, len(str))
+	for i := 0; i < len(str); i++ {
+		if str[i] == '\\' && i+1 < len(str) {
+			i++
+		}
+		b = append(b, str[i])
+	}
+	return string(b)
+}
+
+// parseSel parses a selector string (e.g. `hello[0].world["foo bar"]`)
+// into steps. An empty selector refers to the root value
+func parseSel(sel string) (steps []selStep, err error) {
+	lex := lexSel(sel)
+	go lex.run()
+	defer func() {
+		// drain the items so the lexer goroutine can finish
+		for range lex.items {
+		}
+	}()
+
+	steps = []selStep{}
+	for item := lex.nextItem(); item.typ != selItemEnd; item = lex.nextItem() {
+		switch item.typ {
+		case selItemProp:
+			steps = append(steps, selStep{key: item.val})
+		case selItemDot:
+			if item = lex.nextItem(); item.typ != selItemProp || item.val == "" {
+				return nil, fmt.Errorf("invalid selector %#v: expected property name after dot", sel)
+			}
+			steps = append(steps, selStep{key: item.val})
+		case selItemLeftBrac:
+			var step selStep
+			switch item = lex.nextItem(); item.typ {
+			case selItemNumber:
+				nth, err := strconv.Atoi(item.val)
+				if err != nil || nth < 0 || strconv.Itoa(nth) != item.val {
+					return nil, fmt.Errorf("invalid selector %#v: invalid array index %#v", sel, item.val)
+				}
+				step = selStep{nth: nth, isIndex: true}
+			case selItemString:
+				step = selStep{key: unescapeSel(item.val)}
+			case selItemError:
+				return nil, fmt.Errorf("invalid selector %#v: %s", sel, item.val)
+			default:
+				return nil, fmt.Errorf("invalid selector %#v: empty bracket", sel)
+			}
+			if item = lex.nextItem(); item.typ != selItemRightBrac {
+				return nil, fmt.Errorf("invalid selector %#v: unclosed bracket", sel)
+			}
+			steps = append(steps, step)
+		case selItemError:
+			return nil, fmt.Errorf("invalid selector %#v: %s", sel, item.val)
+		default:
+			return nil, fmt.Errorf("invalid selector %#v: unexpected %#v", sel, item.val)
 		}
 	}
+	return
 }
@@ -137,3 +137,73 @@ func TestSelLex(t *testing.T) {
 	}
 
 }
+
+func TestParseSel(t *testing.T) {
+	type testPair struct {
+		Sel      string
+		Expected []selStep
+	}
+
+	tests := []testPair{
+		testPair{"", []selStep{}},
+		testPair{
+			"hello",
+			[]selStep{
+				selStep{key: "hello"},
+			},
+		},
+		testPair{
+			"user_id.quux",
+			[]selStep{
+				selStep{key: "user_id"},
+				selStep{key: "quux"},
+			},
+		},
+		testPair{
+			".hello[12][3]world",
+			[]selStep{
+				selStep{key: "hello"},
+				selStep{nth: 12, isIndex: true},
+				selStep{nth: 3, isIndex: true},
+				selStep{key: "world"},
+			},
+		},
+		testPair{
+			"[\"foo and \\\"bar\\\"\"]['foo\\'s bar']['']",
+			[]selStep{
+				selStep{key: "foo and \"bar\""},
+				selStep{key: "foo's bar"},
+				selStep{key: ""},
+			},
+		},
+		testPair{
+			"[\"back\\\\\"]",
+			[]selStep{
+				selStep{key: "back\\"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		steps, err := parseSel(test.Sel)
+		if err != nil {
+			t.Errorf("sel=%#v unexpected error: %s", test.Sel, err.Error())
+			continue
+		}
+		if want, have := len(test.Expected), len(steps); want != have {
+			t.Errorf("sel=%#v expected %#v steps, got %#v", test.Sel, want, have)
+			continue
+		}
+		for i := range steps {
+			if want, have := test.Expected[i], steps[i]; want != have {
+				t.Errorf("sel=%#v pos=%#v expected=%#v got=%#v", test.Sel, i, want, have)
+			}
+		}
+	}
+
+	for _, sel := range []string{"hello.", "hello..world", "hello[", "hello[]", "hello[-1]", "hello[01]", "hello[1", "hello]", "['foo"} {
+		if _, err := parseSel(sel); err == nil {
+			t.Errorf("sel=%#v expected error, got nil", sel)
+		}
+	}
+}
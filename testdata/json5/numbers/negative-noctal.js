@@ -0,0 +1 @@
+-098
@@ -0,0 +1 @@
+080
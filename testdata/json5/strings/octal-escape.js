@@ -0,0 +1 @@
+'\101'
@@ -0,0 +1,1026 @@
+package lzjson
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// yamlError describes a malformed or unsupported YAML input.
+// It matches ErrorSyntax with errors.Is, unless the kind is
+// set to another ParseError
+type yamlError struct {
+	msg  string
+	line int
+	kind ParseError
+}
+
+// Error implements error type
+func (err yamlError) Error() string {
+	return fmt.Sprintf("%s at line %d", err.msg, err.line)
+}
+
+// Is tells if the target is the kind of the error, for errors.Is
+func (err yamlError) Is(target error) bool {
+	if err.kind == ErrorUndefined {
+		return target == ErrorSyntax
+	}
+	return target == err.kind
+}
+
+// DecodeYAML reads a YAML document from io.Reader, converts it
+// into JSON, then returns a Node of it.
+//
+// Only the JSON compatible subset of YAML 1.2 is supported: block
+// and flow collections, plain, quoted and block scalars, and
+// comments. Anchors, aliases, tags, complex keys and multiple
+// documents are reported as errors. Plain scalars are resolved
+// by the YAML 1.2 core schema (e.g. `yes` is a string) and
+// Infinity or NaN are refused with ErrorNonFinite.
+//
+// Keys are kept in the order of the document. Errors tell the
+// line number in the YAML document.
+func DecodeYAML(reader io.Reader) Node {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return &rootNode{err: err}
+	}
+	if b, err = fromYAML(b); err != nil {
+		return &rootNode{
+			err: Error{
+				Path: "json",
+				Err:  err,
+			},
+		}
+	}
+	return Parse(b)
+}
+
+// yamlLine is a line of the YAML input
+type yamlLine struct {
+	start  int // offset of the line
+	end    int // offset of the line break, or end of input
+	indent int // number of spaces before the content
+}
+
+// yamlParser converts YAML input into compact JSON
+type yamlParser struct {
+	src   string
+	lines []yamlLine
+	i     int // index of the current line
+	out   bytes.Buffer
+}
+
+// fromYAML converts the YAML document into compact JSON. A
+// document with no value converts to empty bytes
+func fromYAML(b []byte) ([]byte, error) {
+	if !utf8.Valid(b) {
+		i := 0
+		for i < len(b) {
+			r, size := utf8.DecodeRune(b[i:])
+			if r == utf8.RuneError && size == 1 {
+				break
+			}
+			i += size
+		}
+		return nil, yamlError{msg: "invalid UTF-8", line: bytes.Count(b[:i], []byte("\n")) + 1}
+	}
+	p := &yamlParser{src: strings.TrimPrefix(string(b), "\ufeff")}
+	for start := 0; start < len(p.src); {
+		end := strings.IndexByte(p.src[start:], '\n')
+		next := start + end + 1
+		if end < 0 {
+			end = len(p.src) - start
+			next = len(p.src)
+		}
+		l := yamlLine{start: start, end: start + end}
+		if l.end > l.start && p.src[l.end-1] == '\r' {
+			l.end--
+		}
+		for l.start+l.indent < l.end && p.src[l.start+l.indent] == ' ' {
+			l.indent++
+		}
+		p.lines = append(p.lines, l)
+		start = next
+	}
+	if err := p.document(); err != nil {
+		return nil, err
+	}
+	return p.out.Bytes(), nil
+}
+
+// errorf returns a yamlError at the line of the index
+func (p *yamlParser) errorf(i int, format string, args ...interface{}) error {
+	return yamlError{msg: fmt.Sprintf(format, args...), line: i + 1}
+}
+
+// withLine sets the line of the index to the
+// yamlError of resolvePlain, which has no line
+func (p *yamlParser) withLine(err error, i int) error {
+	if yerr, ok := err.(yamlError); ok {
+		yerr.line = i + 1
+		return yerr
+	}
+	return err
+}
+
+// lineOf returns the index of the line of the offset
+func (p *yamlParser) lineOf(off int) int {
+	return sort.Search(len(p.lines), func(i int) bool {
+		return p.lines[i].start > off
+	}) - 1
+}
+
+// content returns the line of the index without indentation
+func (p *yamlParser) content(i int) string {
+	return p.src[p.lines[i].start+p.lines[i].indent : p.lines[i].end]
+}
+
+// blank tells if the line of the index is empty or a comment
+func (p *yamlParser) blank(i int) bool {
+	c := strings.TrimLeft(p.content(i), " \t")
+	return c == "" || c[0] == '#'
+}
+
+// skipBlank skips the blank lines and tells
+// if there are lines left
+func (p *yamlParser) skipBlank() bool {
+	for p.i < len(p.lines) && p.blank(p.i) {
+		p.i++
+	}
+	return p.i < len(p.lines)
+}
+
+// isDocMarker tells if the line of the index is a
+// document start (---) or end (...) marker
+func (p *yamlParser) isDocMarker(i int) bool {
+	if p.lines[i].indent > 0 {
+		return false
+	}
+	c := p.content(i)
+	for _, marker := range []string{"---", "..."} {
+		if strings.HasPrefix(c, marker) && (len(c) == 3 || c[3] == ' ' || c[3] == '\t') {
+			return true
+		}
+	}
+	return false
+}
+
+// atIndent tells if the current line is a content
+// line, other than document markers, at the indent
+func (p *yamlParser) atIndent(indent int) bool {
+	return p.skipBlank() && !p.isDocMarker(p.i) && p.lines[p.i].indent == indent
+}
+
+// isSeqItem tells if the line content is a block sequence entry
+func isSeqItem(c string) bool {
+	return c == "-" || strings.HasPrefix(c, "- ") || strings.HasPrefix(c, "-\t")
+}
+
+// stripComment removes the comment and trailing
+// whitespace from the plain scalar text
+func stripComment(s string) string {
+	for j := 0; j < len(s); j++ {
+		if s[j] == '#' && (j == 0 || s[j-1] == ' ' || s[j-1] == '\t') {
+			s = s[:j]
+			break
+		}
+	}
+	return strings.TrimRight(s, " \t")
+}
+
+// document converts the only document of the input
+func (p *yamlParser) document() error {
+	for p.skipBlank() && p.lines[p.i].indent == 0 && p.content(p.i)[0] == '%' {
+		p.i++ // directive
+	}
+	if !p.skipBlank() {
+		return nil
+	}
+	if c := p.content(p.i); p.isDocMarker(p.i) && c[0] == '-' {
+		if rest := strings.TrimLeft(c[3:], " \t"); rest == "" || rest[0] == '#' {
+			p.i++
+		} else {
+			p.lines[p.i].indent = len(c) - len(rest)
+		}
+	}
+	if p.skipBlank() && !p.isDocMarker(p.i) {
+		if err := p.block(-1); err != nil {
+			return err
+		}
+	}
+	if p.skipBlank() && p.isDocMarker(p.i) && p.content(p.i)[0] == '.' {
+		p.i++
+	}
+	if !p.skipBlank() {
+		return nil
+	}
+	if p.isDocMarker(p.i) {
+		return p.errorf(p.i, "multiple documents are not supported")
+	}
+	return p.errorf(p.i, "unexpected indentation")
+}
+
+// node converts the block node in the lines after the
+// current line, which must be indented more than the
+// parent. An empty node is converted as null
+func (p *yamlParser) node(parent int) error {
+	if !p.skipBlank() || p.isDocMarker(p.i) || p.lines[p.i].indent <= parent {
+		p.out.WriteString("null")
+		return nil
+	}
+	return p.block(parent)
+}
+
+// block converts the block node starting at the current line
+func (p *yamlParser) block(parent int) error {
+	c := p.content(p.i)
+	if c[0] == '\t' {
+		return p.errorf(p.i, "tab in indentation")
+	}
+	if isSeqItem(c) {
+		return p.sequence(p.lines[p.i].indent)
+	}
+	if _, _, ok, err := p.key(p.i); err != nil {
+		return err
+	} else if ok {
+		return p.mapping(p.lines[p.i].indent)
+	}
+	return p.inline(p.lines[p.i].start+p.lines[p.i].indent, parent)
+}
+
+// mapping converts the block mapping at the indent
+func (p *yamlParser) mapping(indent int) error {
+	p.out.WriteByte('{')
+	seen := map[string]bool{}
+	for first := true; p.atIndent(indent); first = false {
+		line := p.i
+		if c := p.content(line); c[0] == '\t' {
+			return p.errorf(line, "tab in indentation")
+		}
+		key, off, ok, err := p.key(line)
+		if err != nil {
+			return err
+		} else if !ok {
+			return p.errorf(line, "could not find expected ':'")
+		}
+		if seen[key] {
+			return p.errorf(line, "duplicate key %#v", key)
+		}
+		seen[key] = true
+		if !first {
+			p.out.WriteByte(',')
+		}
+		writeCanonicalString(&p.out, key)
+		p.out.WriteByte(':')
+
+		rest := strings.TrimLeft(p.src[off:p.lines[line].end], " \t")
+		if rest == "" || rest[0] == '#' {
+			p.i++
+			// a sequence may be at the same indent as its key
+			if p.atIndent(indent) && isSeqItem(p.content(p.i)) {
+				err = p.sequence(indent)
+			} else {
+				err = p.node(indent)
+			}
+		} else {
+			err = p.inline(p.lines[line].end-len(rest), indent)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if p.skipBlank() && !p.isDocMarker(p.i) && p.lines[p.i].indent > indent {
+		return p.errorf(p.i, "unexpected indentation")
+	}
+	p.out.WriteByte('}')
+	return nil
+}
+
+// sequence converts the block sequence at the indent
+func (p *yamlParser) sequence(indent int) error {
+	p.out.WriteByte('[')
+	for n := 0; p.atIndent(indent) && isSeqItem(p.content(p.i)); n++ {
+		if n > 0 {
+			p.out.WriteByte(',')
+		}
+		line := p.i
+		c := p.content(line)
+		rest := strings.TrimLeft(c[1:], " \t")
+
+		var err error
+		if rest == "" || rest[0] == '#' {
+			p.i++
+			err = p.node(indent)
+		} else {
+			// the entry is a block node at the column of the rest
+			p.lines[line].indent += len(c) - len(rest)
+			err = p.block(indent)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if p.skipBlank() && !p.isDocMarker(p.i) && p.lines[p.i].indent > indent {
+		return p.errorf(p.i, "unexpected indentation")
+	}
+	p.out.WriteByte(']')
+	return nil
+}
+
+// key returns the mapping key of the line of the index and
+// the offset right after the colon, or ok = false if the
+// line is not a mapping entry
+func (p *yamlParser) key(i int) (key string, off int, ok bool, err error) {
+	c := p.content(i)
+	start := p.lines[i].start + p.lines[i].indent
+	switch c[0] {
+	case '"', '\'':
+		key, end, err := p.quoted(start)
+		if err != nil {
+			return "", 0, false, err
+		}
+		if end > p.lines[i].end {
+			return "", 0, false, nil // multi-line scalar
+		}
+		j := end
+		for j < p.lines[i].end && (p.src[j] == ' ' || p.src[j] == '\t') {
+			j++
+		}
+		if j < p.lines[i].end && p.src[j] == ':' &&
+			(j+1 == p.lines[i].end || p.src[j+1] == ' ' || p.src[j+1] == '\t') {
+			return key, j + 1, true, nil
+		}
+		return "", 0, false, nil
+	case '?':
+		if len(c) == 1 || c[1] == ' ' || c[1] == '\t' {
+			return "", 0, false, p.errorf(i, "complex keys are not supported")
+		}
+	case '[', '{', '|', '>', '#':
+		return "", 0, false, nil
+	}
+	if isSeqItem(c) {
+		return "", 0, false, nil
+	}
+	for j := 0; j < len(c); j++ {
+		if c[j] == '#' && j > 0 && (c[j-1] == ' ' || c[j-1] == '\t') {
+			break
+		}
+		if c[j] == ':' && (j+1 == len(c) || c[j+1] == ' ' || c[j+1] == '\t') {
+			return strings.TrimRight(c[:j], " \t"), start + j + 1, true, nil
+		}
+	}
+	return "", 0, false, nil
+}
+
+// inline converts the value starting at the offset of the
+// current line. Lines following it belong to the value if
+// indented more than the parent
+func (p *yamlParser) inline(off, parent int) error {
+	switch c := p.src[off]; c {
+	case '[', '{':
+		end, err := p.flow(off)
+		if err != nil {
+			return err
+		}
+		return p.endInline(end)
+	case '"', '\'':
+		str, end, err := p.quoted(off)
+		if err != nil {
+			return err
+		}
+		writeCanonicalString(&p.out, str)
+		return p.endInline(end)
+	case '|', '>':
+		return p.blockScalar(off, parent)
+	case '&', '*', '!':
+		return p.errorf(p.i, "anchors, aliases and tags are not supported")
+	case '@', '`', ',', ']', '}':
+		return p.errorf(p.i, "invalid character %q", c)
+	case '-':
+		if isSeqItem(p.src[off:p.lines[p.i].end]) {
+			return p.errorf(p.i, "sequence entries are not allowed here")
+		}
+	}
+	return p.plain(off, parent)
+}
+
+// endInline checks there is nothing but comment after the
+// inline value ending at the offset, then moves on to the
+// next line
+func (p *yamlParser) endInline(end int) error {
+	i := p.lineOf(end)
+	if rest := strings.TrimLeft(p.src[end:p.lines[i].end], " \t"); rest != "" && rest[0] != '#' {
+		r, _ := utf8.DecodeRuneInString(rest)
+		return p.errorf(i, "invalid character %q", r)
+	}
+	p.i = i + 1
+	return nil
+}
+
+// plain converts the plain scalar starting at the offset of
+// the current line, folding the lines indented more than the parent
+func (p *yamlParser) plain(off, parent int) error {
+	line := p.i
+	raw := strings.TrimRight(p.src[off:p.lines[line].end], " \t")
+	text := stripComment(raw)
+	ended := raw != text // a comment ends the scalar
+	if strings.Contains(text, ": ") || strings.HasSuffix(text, ":") {
+		return p.errorf(line, "mapping values are not allowed here")
+	}
+	folded := []string{text}
+	p.i = line + 1
+	breaks := 0
+	for j := p.i; j < len(p.lines) && !ended; j++ {
+		t := strings.TrimSpace(p.src[p.lines[j].start:p.lines[j].end])
+		if t == "" {
+			breaks++
+			continue
+		}
+		if p.lines[j].indent <= parent || t[0] == '#' || p.isDocMarker(j) {
+			break
+		}
+		raw, t = t, stripComment(t)
+		if ended = raw != t; strings.Contains(t, ": ") || strings.HasSuffix(t, ":") {
+			return p.errorf(j, "mapping values are not allowed here")
+		}
+		if breaks == 0 {
+			folded = append(folded, " ")
+		} else {
+			folded = append(folded, strings.Repeat("\n", breaks))
+		}
+		folded = append(folded, t)
+		breaks = 0
+		p.i = j + 1
+	}
+	b, err := resolvePlain(strings.Join(folded, ""))
+	if err != nil {
+		return p.withLine(err, line)
+	}
+	p.out.Write(b)
+	return nil
+}
+
+// blockScalar converts the literal (|) or folded (>)
+// block scalar with the header at the offset
+func (p *yamlParser) blockScalar(off, parent int) error {
+	line := p.i
+	header := stripComment(p.src[off:p.lines[line].end])
+	chomp, indent := byte(0), 0
+	for _, c := range []byte(header[1:]) {
+		switch {
+		case (c == '-' || c == '+') && chomp == 0:
+			chomp = c
+		case c >= '1' && c <= '9' && indent == 0:
+			if parent < 0 {
+				parent = 0
+			}
+			indent = parent + int(c-'0')
+		default:
+			return p.errorf(line, "invalid block scalar header %#v", header)
+		}
+	}
+
+	// collect the content lines
+	var lines []string
+	p.i = line + 1
+	for ; p.i < len(p.lines); p.i++ {
+		l := p.lines[p.i]
+		if strings.TrimSpace(p.src[l.start:l.end]) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if indent == 0 {
+			indent = l.indent
+		}
+		if l.indent < indent || l.indent <= parent || p.isDocMarker(p.i) {
+			break
+		}
+		lines = append(lines, p.src[l.start+indent:l.end])
+	}
+
+	// trailing empty lines are handled by chomping
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var b strings.Builder
+	if p.src[off] == '|' {
+		b.WriteString(strings.Join(lines, "\n"))
+	} else {
+		breaks, more, first := 0, false, true
+		for _, l := range lines {
+			if l == "" {
+				breaks++
+				continue
+			}
+			lmore := l[0] == ' ' || l[0] == '\t'
+			switch {
+			case first:
+				b.WriteString(strings.Repeat("\n", breaks))
+			case !more && !lmore && breaks == 0:
+				b.WriteByte(' ')
+			case !more && !lmore:
+				b.WriteString(strings.Repeat("\n", breaks))
+			default:
+				b.WriteString(strings.Repeat("\n", breaks+1))
+			}
+			b.WriteString(l)
+			breaks, more, first = 0, lmore, false
+		}
+	}
+	if len(lines) > 0 && chomp != '-' {
+		b.WriteByte('\n')
+	}
+	if chomp == '+' {
+		b.WriteString(strings.Repeat("\n", trailing))
+	}
+	writeCanonicalString(&p.out, b.String())
+	return nil
+}
+
+// quoted returns the decoded single or double quoted scalar
+// at the offset, and the offset right after the closing quote
+func (p *yamlParser) quoted(off int) (string, int, error) {
+	src, q := p.src, p.src[off]
+	var b strings.Builder
+	for i := off + 1; ; {
+		if i >= len(src) {
+			return "", 0, p.errorf(p.lineOf(off), "unterminated quoted scalar")
+		}
+		switch c := src[i]; {
+		case c == q && q == '\'' && i+1 < len(src) && src[i+1] == '\'':
+			b.WriteByte('\'')
+			i += 2
+		case c == q:
+			return b.String(), i + 1, nil
+		case c == '\n' || c == '\r':
+			// fold the line break, trimming the whitespace around
+			s := strings.TrimRight(b.String(), " \t")
+			b.Reset()
+			b.WriteString(s)
+			breaks := -1
+			for i < len(src) && (src[i] == '\n' || src[i] == '\r') {
+				if src[i] == '\r' && i+1 < len(src) && src[i+1] == '\n' {
+					i++
+				}
+				for i++; i < len(src) && (src[i] == ' ' || src[i] == '\t'); i++ {
+				}
+				breaks++
+			}
+			if breaks == 0 {
+				b.WriteByte(' ')
+			} else {
+				b.WriteString(strings.Repeat("\n", breaks))
+			}
+		case c == '\\' && q == '"':
+			i++
+			r, n, err := p.escape(i)
+			if err != nil {
+				return "", 0, err
+			}
+			if r >= 0 {
+				b.WriteRune(r)
+			}
+			i += n
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+}
+
+// yamlEscapes are the single character escape
+// sequences of double quoted scalars
+var yamlEscapes = map[byte]rune{
+	'0': 0, 'a': '\a', 'b': '\b', 't': '\t', '\t': '\t', 'n': '\n',
+	'v': '\v', 'f': '\f', 'r': '\r', 'e': 0x1b, ' ': ' ', '"': '"',
+	'/': '/', '\\': '\\', 'N': 0x85, '_': 0xa0, 'L': 0x2028, 'P': 0x2029,
+}
+
+// escape decodes the escape sequence after the backslash
+// at offset i. It returns the rune (-1 for an escaped line
+// break) and the number of bytes consumed
+func (p *yamlParser) escape(i int) (rune, int, error) {
+	if i >= len(p.src) {
+		return 0, 0, p.errorf(p.lineOf(i), "unterminated quoted scalar")
+	}
+	c := p.src[i]
+	if r, ok := yamlEscapes[c]; ok {
+		return r, 1, nil
+	}
+	switch c {
+	case '\n', '\r':
+		// escaped line break, joining the lines
+		n := 1
+		if c == '\r' && i+1 < len(p.src) && p.src[i+1] == '\n' {
+			n++
+		}
+		for i+n < len(p.src) && (p.src[i+n] == ' ' || p.src[i+n] == '\t') {
+			n++
+		}
+		return -1, n, nil
+	case 'x', 'u', 'U':
+		n := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+		if i+1+n <= len(p.src) {
+			if v, err := strconv.ParseUint(p.src[i+1:i+1+n], 16, 32); err == nil {
+				return rune(v), n + 1, nil
+			}
+		}
+	}
+	return 0, 0, p.errorf(p.lineOf(i), "invalid escape sequence \\%c", c)
+}
+
+// flowSpace returns the offset of the first byte at or after
+// i that is not whitespace, line break or comment
+func (p *yamlParser) flowSpace(i int) int {
+	for i < len(p.src) {
+		switch p.src[i] {
+		case ' ', '\t', '\n', '\r':
+			i++
+		case '#':
+			if end := strings.IndexByte(p.src[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(p.src)
+			}
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// flowPlain returns the plain scalar text in flow context
+// at the offset and the offset right after it
+func (p *yamlParser) flowPlain(i int) (string, int) {
+	j := i
+	for ; j < len(p.src); j++ {
+		c := p.src[j]
+		if strings.IndexByte(",[]{}\n\r", c) >= 0 {
+			break
+		}
+		if c == ':' && (j+1 == len(p.src) || strings.IndexByte(" \t\n\r,[]{}", p.src[j+1]) >= 0) {
+			break
+		}
+		if c == '#' && j > i && (p.src[j-1] == ' ' || p.src[j-1] == '\t') {
+			break
+		}
+	}
+	return strings.TrimRight(p.src[i:j], " \t"), j
+}
+
+// flowScalar returns the quoted or plain scalar in flow context
+// at the offset, the offset right after it, and if it is quoted
+func (p *yamlParser) flowScalar(i int) (string, int, bool, error) {
+	switch c := p.src[i]; c {
+	case '"', '\'':
+		str, end, err := p.quoted(i)
+		return str, end, true, err
+	case '[', '{':
+		return "", 0, false, p.errorf(p.lineOf(i), "complex keys are not supported")
+	case '&', '*', '!':
+		return "", 0, false, p.errorf(p.lineOf(i), "anchors, aliases and tags are not supported")
+	case ',', ']', '}', ':':
+		return "", 0, false, p.errorf(p.lineOf(i), "invalid character %q", c)
+	}
+	str, end := p.flowPlain(i)
+	return str, end, false, nil
+}
+
+// flowValue converts the value in flow context at the
+// offset and returns the offset right after it
+func (p *yamlParser) flowValue(i int) (int, error) {
+	if c := p.src[i]; c == '[' || c == '{' {
+		return p.flow(i)
+	}
+	str, end, quoted, err := p.flowScalar(i)
+	if err != nil {
+		return 0, err
+	}
+	if quoted {
+		writeCanonicalString(&p.out, str)
+		return end, nil
+	}
+	b, err := resolvePlain(str)
+	if err != nil {
+		return 0, p.withLine(err, p.lineOf(i))
+	}
+	p.out.Write(b)
+	return end, nil
+}
+
+// flow converts the flow sequence or mapping at the offset
+// and returns the offset right after it
+func (p *yamlParser) flow(i int) (int, error) {
+	open, close := p.src[i], byte(']')
+	if open == '{' {
+		close = '}'
+	}
+	start := i
+	p.out.WriteByte(open)
+	seen := map[string]bool{}
+	for n := 0; ; n++ {
+		if i = p.flowSpace(i + 1); i >= len(p.src) {
+			return 0, p.errorf(p.lineOf(start), "unterminated flow collection")
+		}
+		if p.src[i] == close {
+			break // empty collection or trailing comma
+		}
+		if n > 0 {
+			p.out.WriteByte(',')
+		}
+
+		var err error
+		if open == '{' {
+			var key string
+			if key, i, _, err = p.flowScalar(i); err != nil {
+				return 0, err
+			}
+			if seen[key] {
+				return 0, p.errorf(p.lineOf(i), "duplicate key %#v", key)
+			}
+			seen[key] = true
+			writeCanonicalString(&p.out, key)
+			p.out.WriteByte(':')
+			if i = p.flowSpace(i); i >= len(p.src) || p.src[i] != ':' {
+				p.out.WriteString("null") // a key without value
+			} else if i = p.flowSpace(i + 1); i >= len(p.src) || p.src[i] == ',' || p.src[i] == '}' {
+				p.out.WriteString("null")
+			} else if i, err = p.flowValue(i); err != nil {
+				return 0, err
+			}
+		} else {
+			if i, err = p.flowValue(i); err != nil {
+				return 0, err
+			}
+			if j := p.flowSpace(i); j < len(p.src) && p.src[j] == ':' {
+				return 0, p.errorf(p.lineOf(j), "mappings in flow sequence are not supported")
+			}
+		}
+
+		if i = p.flowSpace(i); i < len(p.src) && p.src[i] == ',' {
+			continue
+		}
+		if i >= len(p.src) {
+			return 0, p.errorf(p.lineOf(start), "unterminated flow collection")
+		}
+		if p.src[i] != close {
+			return 0, p.errorf(p.lineOf(i), "invalid character %q", p.src[i])
+		}
+		break
+	}
+	p.out.WriteByte(close)
+	return i + 1, nil
+}
+
+var (
+	reYAMLInt   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	reYAMLOct   = regexp.MustCompile(`^0o[0-7]+$`)
+	reYAMLHex   = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
+	reYAMLFloat = regexp.MustCompile(`^([-+]?)([0-9]*)(?:\.([0-9]*))?([eE][-+]?[0-9]+)?$`)
+	reYAMLInf   = regexp.MustCompile(`^[-+]?\.(?:inf|Inf|INF)$`)
+	reYAMLNaN   = regexp.MustCompile(`^\.(?:nan|NaN|NAN)$`)
+)
+
+// resolvePlain resolves the plain scalar by the
+// YAML 1.2 core schema and returns it as JSON
+func resolvePlain(s string) ([]byte, error) {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return []byte("null"), nil
+	case "true", "True", "TRUE":
+		return []byte("true"), nil
+	case "false", "False", "FALSE":
+		return []byte("false"), nil
+	}
+	switch {
+	case reYAMLInf.MatchString(s) || reYAMLNaN.MatchString(s):
+		return nil, yamlError{msg: "non-finite number " + s, kind: ErrorNonFinite}
+	case reYAMLInt.MatchString(s):
+		sign := ""
+		if s[0] == '-' {
+			sign = "-"
+		}
+		digits := strings.TrimLeft(strings.TrimLeft(s, "+-"), "0")
+		if digits == "" {
+			digits = "0"
+		}
+		return []byte(sign + digits), nil
+	case reYAMLOct.MatchString(s) || reYAMLHex.MatchString(s):
+		base := 8
+		if s[1] == 'x' {
+			base = 16
+		}
+		v, _ := new(big.Int).SetString(s[2:], base)
+		return []byte(v.String()), nil
+	}
+	if m := reYAMLFloat.FindStringSubmatch(s); m != nil && m[2]+m[3] != "" {
+		sign, intPart, frac, exp := m[1], strings.TrimLeft(m[2], "0"), m[3], m[4]
+		if sign == "+" {
+			sign = ""
+		}
+		if intPart == "" {
+			intPart = "0"
+		}
+		if frac != "" {
+			frac = "." + frac
+		}
+		return []byte(sign + intPart + frac + exp), nil
+	}
+	var buf bytes.Buffer
+	writeCanonicalString(&buf, s)
+	return buf.Bytes(), nil
+}
+
+// EncodeYAML writes the node as a block style YAML document.
+// Members of objects are written in the order of the JSON.
+// It returns the parse error of the node, or an Error if the
+// node is undefined or is not valid JSON
+func EncodeYAML(w io.Writer, n Node) error {
+	b, err := definedValue(n)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	writeYAML(&buf, b, 0, yamlTop)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// yamlContext is where a YAML value is written
+type yamlContext int
+
+const (
+	yamlTop   yamlContext = iota // at the start of the document
+	yamlKey                      // right after "key:"
+	yamlEntry                    // right after "- "
+)
+
+// writeYAML writes the validated raw JSON value in the
+// context, where the key or entry owning it is at the indent
+func writeYAML(buf *bytes.Buffer, b []byte, indent int, ctx yamlContext) {
+	var members []objMember
+	var elems []arrElem
+	switch b[0] {
+	case '{':
+		members, _, _ = scanObject(b, 0)
+	case '[':
+		elems, _, _ = scanArray(b, 0)
+	}
+
+	switch {
+	case len(members) > 0:
+		inner := indent + 2
+		switch ctx {
+		case yamlTop:
+			inner = indent
+		case yamlKey:
+			buf.WriteByte('\n')
+		}
+		for i, m := range members {
+			if i > 0 || ctx != yamlEntry {
+				buf.WriteString(strings.Repeat(" ", inner))
+			}
+			writeYAMLString(buf, m.key)
+			buf.WriteByte(':')
+			writeYAML(buf, b[m.valStart:m.valEnd], inner, yamlKey)
+		}
+	case len(elems) > 0:
+		inner := indent + 2
+		switch ctx {
+		case yamlTop:
+			inner = indent
+		case yamlKey:
+			buf.WriteByte('\n')
+		}
+		for i, e := range elems {
+			if i > 0 || ctx != yamlEntry {
+				buf.WriteString(strings.Repeat(" ", inner))
+			}
+			buf.WriteString("- ")
+			writeYAML(buf, b[e.start:e.end], inner, yamlEntry)
+		}
+	case b[0] == '"' && yamlLiteral(unquote(b)):
+		if ctx == yamlKey {
+			buf.WriteByte(' ')
+		}
+		str := unquote(b)
+		body := strings.TrimRight(str, "\n")
+		switch len(str) - len(body) {
+		case 0:
+			buf.WriteString("|-\n")
+		case 1:
+			buf.WriteString("|\n")
+		default:
+			buf.WriteString("|+\n")
+		}
+		for _, l := range strings.Split(body, "\n") {
+			if l != "" {
+				buf.WriteString(strings.Repeat(" ", indent+2))
+				buf.WriteString(l)
+			}
+			buf.WriteByte('\n')
+		}
+		if n := len(str) - len(body); n > 1 {
+			buf.WriteString(strings.Repeat("\n", n-1))
+		}
+	default:
+		if ctx == yamlKey {
+			buf.WriteByte(' ')
+		}
+		switch b[0] {
+		case '"':
+			writeYAMLString(buf, unquote(b))
+		case '{':
+			buf.WriteString("{}")
+		case '[':
+			buf.WriteString("[]")
+		default:
+			buf.Write(b)
+		}
+		buf.WriteByte('\n')
+	}
+}
+
+// yamlLiteral tells if the string is written as a literal block scalar
+func yamlLiteral(s string) bool {
+	body := strings.TrimRight(s, "\n")
+	if !strings.Contains(body, "\n") || body[0] == ' ' || body[0] == '\t' {
+		return false
+	}
+	for _, l := range strings.Split(body, "\n") {
+		if l != "" && strings.TrimSpace(l) == "" {
+			return false // whitespace only lines would be lost
+		}
+	}
+	for _, r := range body {
+		if r != '\n' && r != '\t' && !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlPlain tells if the string can be written as a plain
+// scalar, which is read back as the same string
+func yamlPlain(s string) bool {
+	if s == "" || strings.IndexByte("-?:,[]{}#&*!|>'\"%@` \t", s[0]) >= 0 ||
+		strings.HasSuffix(s, " ") || strings.HasSuffix(s, ":") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") ||
+		strings.HasPrefix(s, "...") {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	switch strings.ToLower(s) {
+	case "y", "yes", "n", "no", "on", "off":
+		return false // booleans of YAML 1.1
+	}
+	b, err := resolvePlain(s)
+	return err == nil && b[0] == '"'
+}
+
+// writeYAMLString writes the string as a plain scalar if
+// possible, or a double quoted scalar otherwise
+func writeYAMLString(buf *bytes.Buffer, s string) {
+	if yamlPlain(s) {
+		buf.WriteString(s)
+		return
+	}
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 || (r >= 0x7f && r <= 0x9f) || r == 0xfffe || r == 0xffff {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,244 @@
+package lzjson_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-restit/lzjson"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	n := lzjson.DecodeYAML(strings.NewReader(`# a manifest
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web   # the name
+  labels: {app: web, "tier": 'front end'}
+spec:
+  replicas: 3
+  paused: false
+  selector:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: "nginx:1.25"
+        args: [--port, 8080, 0x1F, 1.5e3, .5, null, ~]
+        ports:
+          - containerPort: 80
+            protocol: TCP
+      - name: sidecar
+        command:
+        - /bin/sh
+        - -c
+        - |
+          echo hello
+          echo world
+`))
+	if err := n.ParseError(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// keys are in the order of the document
+	want := `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","labels":{"app":"web","tier":"front end"}},` +
+		`"spec":{"replicas":3,"paused":false,"selector":null,"template":{"spec":{"containers":[` +
+		`{"name":"web","image":"nginx:1.25","args":["--port",8080,31,1.5e3,0.5,null,null],"ports":[{"containerPort":80,"protocol":"TCP"}]},` +
+		`{"name":"sidecar","command":["/bin/sh","-c","echo hello\necho world\n"]}]}}}}`
+	if have := string(n.Raw()); want != have {
+		t.Errorf("expected %s\ngot      %s", want, have)
+	}
+	if want, have := 80, n.Get("spec").Get("template").Get("spec").Get("containers").GetN(0).Get("ports").GetN(0).Get("containerPort").Int(); want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+}
+
+func TestDecodeYAML_scalars(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		// core schema
+		{"[yes, no, on, True, NULL, '', +12, 007, -0, 0o17, 3., -.5e-3, 1_000]",
+			`["yes","no","on",true,null,"",12,7,-0,15,3,-0.5e-3,"1_000"]`},
+
+		// quoted scalars
+		{`"tab\there \u00e9 \x41 \"q\" \/"`, `"tab\there é A \"q\" /"`},
+		{`'it''s # not a comment'`, `"it's # not a comment"`},
+		{"\"folded\n  line\n\n  break\"", `"folded line\nbreak"`},
+		{"\"joined \\\n  line\"", `"joined line"`},
+
+		// multi-line plain scalars
+		{"a: multi\n  line\n\n  plain # comment\nb: 1", `{"a":"multi line\nplain","b":1}`},
+
+		// block scalars
+		{"a: |\n  keep\n    indent\n\n\nb: 1", `{"a":"keep\n  indent\n","b":1}`},
+		{"a: |-\n  strip\n\n", `{"a":"strip"}`},
+		{"a: |+\n  keep\n\n\nb: 1", `{"a":"keep\n\n\n","b":1}`},
+		{"a: >\n  folded\n  text\n\n  next\n    more\n  end\n", `{"a":"folded text\nnext\n  more\nend\n"}`},
+		{"- |2\n    indented\n", `["  indented\n"]`},
+
+		// collections
+		{"- - a\n  - b\n- k: v\n  k2: v2\n-\n- []\n- {}", `[["a","b"],{"k":"v","k2":"v2"},null,[],{}]`},
+		{"key:\n- same\n- indent\nnext: 1", `{"key":["same","indent"],"next":1}`},
+		{"{a: [1, 2,], b, c: {d: e},\n  # comment\n  f: \"g\"}", `{"a":[1,2],"b":null,"c":{"d":"e"},"f":"g"}`},
+		{"url: http://example.com:8080/a#b", `{"url":"http://example.com:8080/a#b"}`},
+		{"\"quoted key\": 1\n'single': 2", `{"quoted key":1,"single":2}`},
+
+		// documents
+		{"%YAML 1.2\n---\na: 1\n...\n", `{"a":1}`},
+		{"--- text", `"text"`},
+		{"# only comment\n", ``},
+		{"", ``},
+	}
+	for _, test := range tests {
+		n := lzjson.DecodeYAML(strings.NewReader(test.input))
+		if err := n.ParseError(); err != nil {
+			t.Errorf("%#v: unexpected error: %s", test.input, err)
+		} else if want, have := test.want, string(n.Raw()); want != have {
+			t.Errorf("%#v: expected %s, got %s", test.input, want, have)
+		}
+	}
+}
+
+func TestDecodeYAML_error(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+		kind  error
+	}{
+		{"a: 1\nb:\n\tc: 2", "json: tab in indentation at line 3", lzjson.ErrorSyntax},
+		{"a: 1\n  b: 2", "json: mapping values are not allowed here at line 2", lzjson.ErrorSyntax},
+		{"a:\n  b: 1\n c: 2", "json: unexpected indentation at line 3", lzjson.ErrorSyntax},
+		{"a: x # comment\n  y", "json: unexpected indentation at line 2", lzjson.ErrorSyntax},
+		{"a: 1\nb\n", "json: could not find expected ':' at line 2", lzjson.ErrorSyntax},
+		{"a: 1\na: 2", `json: duplicate key "a" at line 2`, lzjson.ErrorSyntax},
+		{"a: 1\nb: \"open\n\n", "json: unterminated quoted scalar at line 2", lzjson.ErrorSyntax},
+		{"a: [1, 2\n", "json: unterminated flow collection at line 1", lzjson.ErrorSyntax},
+		{"a: [1, 2\nb: 1", "json: invalid character 'b' at line 2", lzjson.ErrorSyntax},
+		{"a: &x 1\nb: *x", "json: anchors, aliases and tags are not supported at line 1", lzjson.ErrorSyntax},
+		{"? complex\n: key", "json: complex keys are not supported at line 1", lzjson.ErrorSyntax},
+		{"a: 1\n---\nb: 2", "json: multiple documents are not supported at line 2", lzjson.ErrorSyntax},
+		{"a:\n  - 1\n  - .inf", "json: non-finite number .inf at line 3", lzjson.ErrorNonFinite},
+		{"a: \"\\q\"", `json: invalid escape sequence \q at line 1`, lzjson.ErrorSyntax},
+		{"a: [1, b: 2]", "json: mappings in flow sequence are not supported at line 1", lzjson.ErrorSyntax},
+		{"a: \"x\" y", "json: invalid character 'y' at line 1", lzjson.ErrorSyntax},
+		{"a: 1\n\xff: 2", "json: invalid UTF-8 at line 2", lzjson.ErrorSyntax},
+	}
+	for _, test := range tests {
+		err := lzjson.DecodeYAML(strings.NewReader(test.input)).ParseError()
+		if err == nil {
+			t.Errorf("%#v: expected error %#v, got nil", test.input, test.err)
+			continue
+		}
+		if want, have := test.err, err.Error(); want != have {
+			t.Errorf("%#v: expected %#v, got %#v", test.input, want, have)
+		}
+		if !errors.Is(err, test.kind) {
+			t.Errorf("%#v: expected %#v, got %#v", test.input, test.kind, err)
+		}
+	}
+}
+
+func TestEncodeYAML(t *testing.T) {
+	n := lzjson.ParseString(`{
+		"apiVersion": "v1",
+		"kind": "ConfigMap",
+		"metadata": {"name": "config", "labels": {}},
+		"data": {
+			"script.sh": "#!/bin/sh\necho hello\n",
+			"empty": "",
+			"flag": "yes",
+			"number": "8080",
+			"colon": "a: b",
+			"quote": "tab\tand \"quote\"",
+			"weird key: x": null
+		},
+		"items": [1, -2.5e3, true, [], [["a", "b"], "c"], {"k": "v", "k2": [{"id": 1}]}]
+	}`)
+	var buf bytes.Buffer
+	if err := lzjson.EncodeYAML(&buf, n); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	want := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  labels: {}
+data:
+  script.sh: |
+    #!/bin/sh
+    echo hello
+  empty: ""
+  flag: "yes"
+  number: "8080"
+  colon: "a: b"
+  quote: "tab\tand \"quote\""
+  "weird key: x": null
+items:
+  - 1
+  - -2.5e3
+  - true
+  - []
+  - - - a
+      - b
+    - c
+  - k: v
+    k2:
+      - id: 1
+`
+	if have := buf.String(); want != have {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, have)
+	}
+
+	// round trip
+	back := lzjson.DecodeYAML(strings.NewReader(buf.String()))
+	if err := back.ParseError(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !lzjson.Equal(n, back) {
+		t.Errorf("expected %s, got %s", n.Compact().Raw(), back.Raw())
+	}
+}
+
+func TestEncodeYAML_roundTrip(t *testing.T) {
+	for _, input := range []string{
+		`"top level"`,
+		`"multi\nline"`,
+		`"keep\n\n\n"`,
+		`"  leading space\nline"`,
+		`"blank\n  \nline"`,
+		`"- dash"`,
+		`"..."`,
+		`"\u0085 next line \u2028"`,
+		`[".inf", "0x1F", "1_000", "", "~", "null", "# hash", "end:"]`,
+		`{"": {"a": [{}]}}`,
+		`42`,
+		`null`,
+	} {
+		n := lzjson.ParseString(input)
+		var buf bytes.Buffer
+		if err := lzjson.EncodeYAML(&buf, n); err != nil {
+			t.Errorf("%s: unexpected error: %s", input, err)
+			continue
+		}
+		back := lzjson.DecodeYAML(strings.NewReader(buf.String()))
+		if err := back.ParseError(); err != nil {
+			t.Errorf("%s: unexpected error: %s\n%s", input, err, buf.String())
+		} else if !lzjson.Equal(n, back) {
+			t.Errorf("%s: got %s from\n%s", input, back.Raw(), buf.String())
+		}
+	}
+}
+
+func TestEncodeYAML_error(t *testing.T) {
+	var buf bytes.Buffer
+	err := lzjson.EncodeYAML(&buf, lzjson.ParseString(`{"a": 1}`).Get("b"))
+	if !errors.Is(err, lzjson.ErrorUndefined) {
+		t.Errorf("expected ErrorUndefined, got %#v", err)
+	}
+	if want, have := 0, buf.Len(); want != have {
+		t.Errorf("expected %d, got %d", want, have)
+	}
+}